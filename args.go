@@ -0,0 +1,207 @@
+package fflag
+
+import (
+	"strings"
+
+	"github.com/EmmetCaulfield/fflag/pkg/types"
+)
+
+// ArgStyle selects how `Args()` renders flags when reconstructing a
+// command line from the parsed state of a `FlagSet`.
+type ArgStyle int8
+
+const (
+	// Render flags in long form (`--name=value`) wherever a long
+	// version of the flag exists, falling back to the short form.
+	LongArgStyle ArgStyle = iota
+	// Render flags in short form wherever a short version of the flag
+	// exists, clustering nullary flags (e.g. `-abc`) and falling back
+	// to the long form where the short form cannot carry the value
+	// unambiguously.
+	ShortArgStyle
+)
+
+// Function `Args()` reconstructs a canonical command line from the
+// parsed state of a `FlagSet`, suitable for audit logging or for
+// re-executing the program. Every flag that appeared on the command
+// line is rendered in the given style, using `GetValue()` (and so the
+// flag's `ListSeparator`) for its value, followed by `--` and the
+// operands, if there are any.
+//
+// Parsing the result with a `FlagSet` having the same flag
+// definitions should reproduce the same values. Booleans are rendered
+// only if they differ from their default and counters are repeated as
+// many times as they were counted. Aliases are never rendered, since
+// their appearances are recorded against the flag they alias, and a
+// file-reader flag is rendered via a flag sharing the same value that
+// is not a file reader, if there is one, since the file itself may
+// not be available when the command line is reused.
+func (fs *FlagSet) Args(style ArgStyle) []string {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	args := []string{}
+	cluster := ""
+	operands := []string{}
+	done := map[interface{}]struct{}{}
+
+	for _, g := range fs.Groups {
+		for _, f := range g.FlagList {
+			if f.IsAlias() || f.Count < 1 {
+				continue
+			}
+			if f.IsFileReader() {
+				f = fs.nonReaderFor(f)
+				if f == nil {
+					continue
+				}
+			}
+			if _, ok := done[f.Value]; ok {
+				continue
+			}
+			done[f.Value] = struct{}{}
+
+			if f.IsOperand() {
+				operands = append(operands, f.valueArgs(style)...)
+				continue
+			}
+			if f.IsHyphenNum() {
+				args = append(args, "-"+f.GetValue())
+				continue
+			}
+			if f.IsCounter() || f.IsBool() {
+				n := f.Count
+				if !f.IsCounter() {
+					if !f.boolChanged() {
+						continue
+					}
+					n = 1
+				}
+				if style == ShortArgStyle && f.Short != NoShort {
+					cluster += strings.Repeat(string(f.Short), n)
+					continue
+				}
+				for i := 0; i < n; i++ {
+					args = append(args, f.nullaryArg(style))
+				}
+				continue
+			}
+			if types.IsSetter(f.Value) {
+				// We can't get the value of something implementing
+				// `SetValue` unless it tells us what it is
+				if _, ok := f.Value.(interface{ String() string }); !ok {
+					continue
+				}
+			} else if !f.IsScalar() && types.SliceLen(f.Value) < 1 && types.MapLen(f.Value) < 1 {
+				continue
+			}
+			args = append(args, f.valueArgs(style)...)
+		}
+	}
+	if cluster != "" {
+		args = append([]string{"-" + cluster}, args...)
+	}
+	args = append(args, operands...)
+	if len(*fs.OutputArgs) > 0 {
+		args = append(args, "--")
+		args = append(args, []string(*fs.OutputArgs)...)
+	}
+	return args
+}
+
+// Function `Args()` reconstructs the command line from the default
+// `FlagSet`.
+func Args(style ArgStyle) []string {
+	return CommandLine.Args(style)
+}
+
+// Function `nonReaderFor()` finds a flag that shares a value with the
+// given file-reader flag, but is not itself a file reader.
+func (fs *FlagSet) nonReaderFor(reader *Flag) *Flag {
+	for _, g := range fs.Groups {
+		for _, f := range g.FlagList {
+			if f.Value == reader.Value && !f.IsFileReader() && !f.IsAlias() {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// Function `boolChanged()` reports whether a boolean flag has a value
+// different from the one it would have if it had not appeared on the
+// command line, using the same notion of default as `Set(nil)`.
+func (f *Flag) boolChanged() bool {
+	boolp, ok := f.Value.(*bool)
+	if !ok {
+		// Something implementing `SetValue` that takes no argument
+		return true
+	}
+	def, _ := f.GetDefault().(bool)
+	return *boolp != def
+}
+
+// Function `nullaryArg()` renders a flag without an option-argument.
+func (f *Flag) nullaryArg(style ArgStyle) string {
+	if f.Long != NoLong && (style == LongArgStyle || f.Short == NoShort) {
+		return "--" + f.Long
+	}
+	return "-" + string(f.Short)
+}
+
+// Function `valueArgs()` renders a flag with its option-argument.
+func (f *Flag) valueArgs(style ArgStyle) []string {
+	if f.HasNArgs() {
+		return f.nargsArgs(style)
+	}
+	value := f.GetValue()
+	if setter, ok := f.Value.(interface{ String() string }); ok && types.IsSetter(f.Value) {
+		value = setter.String()
+	}
+	if f.IsOperand() {
+		return []string{f.Long + "=" + value}
+	}
+	useLong := f.Long != NoLong && (style == LongArgStyle || f.Short == NoShort)
+	// A detached optarg is only unambiguous if it can't be mistaken
+	// for a flag and the flag doesn't have an optional default
+	if !useLong && f.Long != NoLong && f.Type.TstDefOptionalBit() {
+		useLong = true
+	}
+	if useLong {
+		return []string{"--" + f.Long + "=" + value}
+	}
+	if f.Type.TstDefOptionalBit() || (strings.HasPrefix(value, "-") && value != "-") {
+		// An attached optarg is unambiguous in these cases because
+		// the rest of a cluster beginning with a hyphen can't be a
+		// short flag
+		return []string{"-" + string(f.Short) + value}
+	}
+	return []string{"-" + string(f.Short), value}
+}
+
+// Function `nargsArgs()` renders a flag taking several
+// option-arguments with each as a separate argument, repeating the
+// flag as necessary for a slice holding the arguments of several
+// appearances.
+func (f *Flag) nargsArgs(style ArgStyle) []string {
+	n := types.SliceLen(f.Value)
+	if types.IsArray(f.Value) && n > f.MaxArgs {
+		n = f.MaxArgs
+	}
+	args := []string{}
+	for i := 0; i < n; {
+		take := f.MaxArgs
+		if take > n-i {
+			take = n - i
+		}
+		// Leave enough for another appearance
+		if rest := n - i - take; rest > 0 && rest < f.MinArgs && take-f.MinArgs+rest > 0 {
+			take -= f.MinArgs - rest
+		}
+		args = append(args, f.nullaryArg(style))
+		for j := i; j < i+take; j++ {
+			args = append(args, types.StrConv(types.ItemAt(f.Value, j), f.convOptions()...))
+		}
+		i += take
+	}
+	return args
+}
@@ -0,0 +1,155 @@
+package fflag
+
+import (
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The bound values of the flags in `argsTestFlagSet()`
+type argsTestValues struct {
+	Ant     bool
+	Bat     bool
+	Verbose uint
+	Snake   string
+	Num     int
+	List    []string
+	Color   string
+	Lines   uint
+	Ops     []string
+}
+
+func argsTestFlagSet(v *argsTestValues) *FlagSet {
+	fs := NewFlagSet(WithSilentFail(), WithContinueOnFail())
+	v.List = []string{}
+	v.Color = "never"
+	fs.Var(&v.Ant, 'a', "ant", "six legs")
+	fs.Var(&v.Bat, 'b', "bat", "two legs, two wings", WithDefault(true))
+	fs.Var(&v.Verbose, 'v', "verbose", "be noisier", AsCounter())
+	fs.Var(&v.Snake, 's', "snake", "no legs")
+	fs.Var(&v.Num, 'n', NoLong, "a number")
+	fs.Var(&v.List, 'l', "list", "a list")
+	fs.Var(&v.Color, NoShort, "color", "when to use color",
+		WithOptionalDefault([]string{"auto", "never", "always"}))
+	fs.Var(&v.Lines, NoShort, NoLong, "a number of lines")
+	return fs
+}
+
+const argsTestRunes = "abz -=,.x/"
+
+func argsTestString(r *rand.Rand, n int) string {
+	buf := strings.Builder{}
+	for i := r.Intn(n + 1); i > 0; i-- {
+		buf.WriteByte(argsTestRunes[r.Intn(len(argsTestRunes))])
+	}
+	return buf.String()
+}
+
+// Generate() makes `argsTestValues` a `quick.Generator`. Values that
+// are left at their zero value (or default) are omitted from argv.
+func (argsTestValues) Generate(r *rand.Rand, size int) reflect.Value {
+	v := argsTestValues{
+		Ant:     r.Intn(2) == 0,
+		Bat:     r.Intn(2) == 0,
+		Verbose: uint(r.Intn(4)),
+		Snake:   argsTestString(r, size),
+		Num:     r.Intn(2000) - 1000,
+		List:    []string{},
+		Color:   []string{"", "auto", "never", "always"}[r.Intn(4)],
+		Lines:   uint(r.Intn(100)),
+		Ops:     []string{},
+	}
+	for i := r.Intn(4); i > 0; i-- {
+		v.List = append(v.List, strings.ReplaceAll(argsTestString(r, 4), ",", ""))
+	}
+	for i := r.Intn(4); i > 0; i-- {
+		v.Ops = append(v.Ops, argsTestString(r, 4))
+	}
+	return reflect.ValueOf(v)
+}
+
+// Function `argv()` produces a (non-canonical) command line for the
+// values
+func (v argsTestValues) argv() []string {
+	argv := []string{}
+	if v.Ant {
+		argv = append(argv, "--ant")
+	}
+	if !v.Bat {
+		argv = append(argv, "-b")
+	}
+	for i := uint(0); i < v.Verbose; i++ {
+		argv = append(argv, "-v")
+	}
+	if v.Snake != "" {
+		argv = append(argv, "--snake", v.Snake)
+		if strings.HasPrefix(v.Snake, "-") {
+			argv = append(argv[:len(argv)-2], "--snake="+v.Snake)
+		}
+	}
+	if v.Num != 0 {
+		argv = append(argv, "-n"+strconv.Itoa(v.Num))
+	}
+	for _, item := range v.List {
+		argv = append(argv, "--list="+item)
+	}
+	if v.Color != "" {
+		argv = append(argv, "--color="+v.Color)
+	}
+	if v.Lines != 0 {
+		argv = append(argv, "-"+strconv.Itoa(int(v.Lines)))
+	}
+	return append(append(argv, "--"), v.Ops...)
+}
+
+func TestArgsRoundtrip(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixEquals = true
+	PosixDoubleHyphen = true
+
+	roundtrip := func(style ArgStyle) func(argsTestValues) bool {
+		return func(in argsTestValues) bool {
+			var v1, v2 argsTestValues
+			fs1 := argsTestFlagSet(&v1)
+			fs1.Parse(in.argv())
+			v1.Ops = []string(*fs1.OutputArgs)
+
+			args := fs1.Args(style)
+			fs2 := argsTestFlagSet(&v2)
+			fs2.Parse(args)
+			v2.Ops = []string(*fs2.OutputArgs)
+			if !assert.Equal(t, v1, v2, "argv: %q, canonical: %q", in.argv(), args) {
+				return false
+			}
+			// Canonical output is a fixed point
+			return assert.Equal(t, args, fs2.Args(style))
+		}
+	}
+	assert.NoError(t, quick.Check(roundtrip(LongArgStyle), nil))
+	assert.NoError(t, quick.Check(roundtrip(ShortArgStyle), nil))
+}
+
+func TestArgsStyle(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixEquals = true
+	PosixDoubleHyphen = true
+	PosixOperandStop = true
+
+	var v argsTestValues
+	fs := argsTestFlagSet(&v)
+	fs.Parse([]string{"-avv", "-b", "-s", "python", "-n-3", "--list", "x,y",
+		"--color", "-12", "operand"})
+	assert.Equal(t,
+		[]string{"--ant", "--bat", "--verbose", "--verbose", "--snake=python",
+			"-n-3", "--list=x,y", "--color=auto", "-12", "--", "operand"},
+		fs.Args(LongArgStyle))
+	assert.Equal(t,
+		[]string{"-abvv", "-s", "python", "-n-3", "-l", "x,y", "--color=auto",
+			"-12", "--", "operand"},
+		fs.Args(ShortArgStyle))
+}
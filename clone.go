@@ -0,0 +1,188 @@
+package fflag
+
+import (
+	"log"
+	"reflect"
+
+	"github.com/EmmetCaulfield/fflag/pkg/deque"
+	"github.com/EmmetCaulfield/fflag/pkg/trie"
+)
+
+// Function `Clone()` returns an independent copy of a `FlagSet` with
+// the same groups, flags, aliases, mutexes, and settings, in which
+// every flag is bound to newly-allocated storage holding a copy of
+// the current value. Flags sharing storage in the original share
+// storage in the copy. Parsing the copy does not affect the original
+// or its bound variables, so a server can define a `FlagSet` once and
+// parse a clone of it per request, concurrently, reading the results
+// with `Get()` or `Lookup()`.
+//
+// Slices and maps are copied, but values implementing `SetValue` are
+// copied shallowly, and callbacks are shared, so they must themselves
+// be safe for concurrent use if clones are used concurrently.
+func (fs *FlagSet) Clone() *FlagSet {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	c := &FlagSet{
+		Groups:           []*FlagGroup{},
+		LongTrie:         trie.NewTrie[Flag](),
+		ShortDict:        map[rune]*Flag{},
+		Output:           fs.Output,
+		HelpOutput:       fs.HelpOutput,
+		Reporter:         fs.Reporter,
+		ExitFunc:         fs.ExitFunc,
+		Quiet:            fs.Quiet,
+		IgnoreDoubleDash: fs.IgnoreDoubleDash,
+		LongW:            fs.LongW,
+		SingleDash:       fs.SingleDash,
+		SingleDashAbbrev: fs.SingleDashAbbrev,
+		PlusOptions:      fs.PlusOptions,
+		InputArgs:        &deque.Deque[string]{},
+		OutputArgs:       &deque.Deque[string]{},
+		OnFail:           fs.OnFail,
+		FailExitCode:     fs.FailExitCode,
+		OnFileError:      fs.OnFileError,
+		FileErrExitCode:  fs.FileErrExitCode,
+		Mutex:            map[string]*Flag{},
+	}
+	if fs.Dialect != nil {
+		d := *fs.Dialect
+		c.Dialect = &d
+	}
+
+	// Copy every flag first, so that aliases can be pointed at the
+	// copies of their targets whatever the order of definition
+	values := map[interface{}]interface{}{}
+	flags := map[*Flag]*Flag{}
+	for _, g := range fs.Groups {
+		for _, f := range g.FlagList {
+			n := &Flag{}
+			*n = *f
+			n.Value = cloneValue(values, f.Value)
+			n.Mutexes = map[string]struct{}{}
+			for name, _ := range f.Mutexes {
+				n.Mutexes[name] = struct{}{}
+			}
+			if f.seenKeys != nil {
+				n.seenKeys = map[string]struct{}{}
+				for key := range f.seenKeys {
+					n.seenKeys[key] = struct{}{}
+				}
+			}
+			n.parentFlagSet = c
+			flags[f] = n
+		}
+	}
+	for i, g := range fs.Groups {
+		c.NewFlagGroup(g.Title).Operands = g.Operands
+		c.GroupIndex = i
+		for _, f := range g.FlagList {
+			n := flags[f]
+			if n.AliasFor != nil {
+				n.AliasFor = flags[n.AliasFor]
+			}
+			err := c.AddFlag(n)
+			if err != nil {
+				log.Panicf("failed to add flag '%s' to clone: %v", n, err)
+			}
+		}
+	}
+	c.GroupIndex = fs.GroupIndex
+	for name, f := range fs.Mutex {
+		c.Mutex[name] = flags[f]
+	}
+	c.InputArgs.Append([]string(*fs.InputArgs)...)
+	c.OutputArgs.Append([]string(*fs.OutputArgs)...)
+	return c
+}
+
+// Function `Get()` returns a copy of the current value of the flag
+// identified by a long-option string or short-option rune (see
+// `Lookup()`), or `nil` if there is no such flag. It is safe to call
+// concurrently with `Parse()`, and sees the value either before or
+// after the parse, never part-way through it.
+func (fs *FlagSet) Get(item interface{}) interface{} {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	f := fs.Lookup(item)
+	if key, ok := item.(string); ok && f == nil {
+		f = fs.LookupKey(key)
+	}
+	if f == nil {
+		return nil
+	}
+	if f.AliasFor != nil {
+		f = f.AliasFor
+	}
+	rv := reflect.ValueOf(f.Value)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return f.Value
+	}
+	return copyValue(rv.Elem()).Interface()
+}
+
+// Function `cloneValue()` allocates new storage for the value pointed
+// to by `v` and copies the value into it, unless the same pointer has
+// been seen before, in which case the same new storage is returned.
+func cloneValue(seen map[interface{}]interface{}, v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return v
+	}
+	if c, ok := seen[v]; ok {
+		return c
+	}
+	c := reflect.New(rv.Elem().Type())
+	c.Elem().Set(copyValue(rv.Elem()))
+	seen[v] = c.Interface()
+	return seen[v]
+}
+
+// Function `copyValue()` copies a value so that the copy doesn't share
+// the backing array of a slice, the contents of a map, or, for any
+// other value, the variable it was read from.
+func copyValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(c, v)
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), iter.Value())
+		}
+		return c
+	}
+	// Not `v` itself, which may be addressable and change under us
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	return c
+}
+
+// Function `restoreValue()` sets a value to a copy of another,
+// truncating a slice, rather than replacing it, and emptying and
+// refilling a map, so that they keep their identity.
+func restoreValue(dst reflect.Value, src reflect.Value) {
+	switch {
+	case dst.Kind() == reflect.Slice && !dst.IsNil():
+		dst.Set(reflect.AppendSlice(dst.Slice(0, 0), src))
+	case dst.Kind() == reflect.Map && !dst.IsNil():
+		for _, key := range dst.MapKeys() {
+			dst.SetMapIndex(key, reflect.Value{})
+		}
+		iter := src.MapRange()
+		for iter.Next() {
+			dst.SetMapIndex(iter.Key(), iter.Value())
+		}
+	default:
+		dst.Set(copyValue(src))
+	}
+}
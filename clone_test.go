@@ -0,0 +1,163 @@
+package fflag
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type cloneTestValues struct {
+	Ant   bool
+	Bat   bool
+	Num   int
+	Name  string
+	List  []string
+	Color string
+}
+
+func cloneTestFlagSet(v *cloneTestValues) *FlagSet {
+	v.List = []string{}
+	v.Color = "never"
+	fs := NewFlagSet(WithSilentFail(), WithContinueOnFail(), WithDialect(GnuDialect))
+	fs.Var(&v.Ant, 'a', "ant", "six legs", InMutex("pet"))
+	fs.Var(&v.Bat, 'b', "bat", "two legs, two wings", InMutex("pet"))
+	fs.Var(&v.Num, 'n', "number", "a number", WithAlias('N', "num", false))
+	fs.Var(&v.Name, 's', "name", "a name", WithDefault("anon"))
+	fs.Var(&v.List, 'l', "list", "a list")
+	fs.Var(&v.Color, NoShort, "color", "when to use color",
+		WithOptionalDefault([]string{"auto", "never", "always"}))
+	return fs
+}
+
+func TestClone(u *testing.T) {
+	t := assert.TestingT(u)
+	var v cloneTestValues
+	fs := cloneTestFlagSet(&v)
+	fs.Parse([]string{"-l", "x"})
+	assert.Equal(t, []string{"x"}, v.List)
+
+	c := fs.Clone()
+	c.Parse([]string{"-a", "op", "-N", "5", "--name", "joe", "-l", "y", "--color"})
+	// The original and its variables are unaffected
+	assert.Equal(t, cloneTestValues{List: []string{"x"}, Name: "anon", Color: "never"}, v)
+	assert.Equal(t, 0, len(*fs.OutputArgs))
+	assert.Equal(t, 0, fs.Lookup('a').Count)
+	// The clone has its own values, starting from the original's
+	assert.Equal(t, true, c.Get('a'))
+	assert.Equal(t, false, c.Get("bat"))
+	assert.Equal(t, 5, c.Get("number"))
+	assert.Equal(t, 5, c.Get("num"), "alias reads its target")
+	assert.Equal(t, "joe", c.Get('s'))
+	assert.Equal(t, []string{"x", "y"}, c.Get("list"))
+	assert.Equal(t, "auto", c.Get("color"))
+	assert.Nil(t, c.Get("nope"))
+	assert.Equal(t, []string{"op"}, []string(*c.OutputArgs))
+	assert.Same(t, c.Lookup("number"), c.Lookup('N').AliasFor)
+	assert.Same(t, c, c.Lookup('N').ParentFlagSet())
+
+	// Mutexes are per-clone
+	c.Parse([]string{"-b"})
+	assert.Equal(t, false, c.Get("bat"), "mutex collision in the clone")
+	fs.Parse([]string{"-b"})
+	assert.Equal(t, true, v.Bat, "no collision in the original")
+
+	// A reset clone returns to the original's values at creation
+	c.Reset()
+	assert.Equal(t, "anon", c.Get("name"))
+	assert.Equal(t, []string{}, c.Get("list"))
+
+	// `Get()` returns a copy
+	list := c.Get("list").([]string)
+	c.Parse([]string{"-l", "z"})
+	assert.Equal(t, []string{}, list)
+	assert.Equal(t, []string{"--bat", "--list=x"}, fs.Args(LongArgStyle))
+	assert.Equal(t, []string{"--list=z"}, c.Args(LongArgStyle))
+}
+
+func TestCloneShared(u *testing.T) {
+	t := assert.TestingT(u)
+	var lines uint
+	fs := NewFlagSet(WithSilentFail(), WithContinueOnFail())
+	fs.Var(&lines, 'n', "lines", "number of lines")
+	fs.Var(&lines, NoShort, NoLong, "number of lines")
+	c := fs.Clone()
+	c.Parse([]string{"-12"})
+	assert.Equal(t, uint(0), lines)
+	assert.Equal(t, uint(12), c.Get('n'))
+	assert.Same(t, c.Lookup('n').Value, c.Lookup(NoShort).Value)
+	assert.NotSame(t, fs.Lookup('n').Value, c.Lookup('n').Value)
+}
+
+func TestDialect(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixEquals = true
+	PosixDoubleHyphen = true
+	PosixOperandStop = true
+	var v cloneTestValues
+
+	fs := cloneTestFlagSet(&v)
+	fs.Parse([]string{"op", "-a"})
+	assert.Equal(t, true, v.Ant, "GNU dialect ignores the globals")
+
+	fs = cloneTestFlagSet(&v)
+	fs.Dialect = nil
+	fs.Parse([]string{"op", "-b"})
+	assert.Equal(t, false, v.Bat, "no dialect uses the globals")
+
+	PosixOperandStop = false
+	fs = cloneTestFlagSet(&v)
+	fs.Dialect = &PosixDialect
+	fs.Parse([]string{"op", "-b"})
+	assert.Equal(t, false, v.Bat)
+	assert.Equal(t, CurrentDialect(), Dialect{true, true, false, false, false})
+	PosixOperandStop = true
+}
+
+// Run with `-race`
+func TestConcurrentParse(u *testing.T) {
+	t := assert.TestingT(u)
+	var v cloneTestValues
+	fs := cloneTestFlagSet(&v)
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := fs.Clone()
+			args := []string{"-n", strconv.Itoa(i), "-l", fmt.Sprint(i), "op"}
+			if i%2 == 0 {
+				args = append(args, "-a")
+			} else {
+				args = append(args, "-b")
+			}
+			c.Parse(args)
+			assert.Equal(t, i, c.Get('n'))
+			assert.Equal(t, []string{fmt.Sprint(i)}, c.Get('l'))
+			assert.Equal(t, i%2 == 0, c.Get('a'))
+			assert.Equal(t, i%2 == 1, c.Get('b'))
+			assert.Equal(t, []string{"op"}, []string(*c.OutputArgs))
+		}(i)
+	}
+
+	// Concurrent parsing and reading of one shared `FlagSet`
+	shared := fs.Clone()
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			shared.Reset()
+			shared.Parse([]string{"-n", strconv.Itoa(i)})
+		}(i)
+		go func() {
+			defer wg.Done()
+			n := shared.Get("number").(int)
+			assert.True(t, n >= 0 && n < 8)
+			_ = shared.Args(ShortArgStyle)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 0, v.Num, "original unaffected")
+}
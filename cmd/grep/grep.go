@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/EmmetCaulfield/fflag"
@@ -84,10 +85,9 @@ func (o *OptStruct) Dump() {
 	fmt.Println("}")
 }
 
-func ValidateRegex(f *fflag.Flag, arg string, pos int) error {
-	fmt.Printf("validating: '%s', '%s', %d\n", f, arg, pos)
-	f.SetOnly(arg, pos)
-	return nil
+func ValidateRegex(v interface{}) error {
+	_, err := regexp.Compile(v.(string))
+	return err
 }
 
 func setup() *OptStruct {
@@ -102,9 +102,9 @@ func setup() *OptStruct {
 	fflag.Var(&opt.PerlRegexp, 'P', "perl-regexp", "PATTERNS are Perl regular expressions",
 		fflag.InMutex("pat-type"))
 	fflag.Var(&opt.Regexp, 'e', "regexp", "use PATTERNS for matching", fflag.WithTypeTag("PATTERNS"),
-		fflag.WithCallback(ValidateRegex))
+		fflag.WithValidator(ValidateRegex))
 	fflag.Var(&opt.Regexp, 'f', "file", "take PATTERNS from FILE", fflag.WithTypeTag("FILE"),
-		fflag.ReadFile(), fflag.WithCallback(ValidateRegex))
+		fflag.ReadFile(), fflag.WithValidator(ValidateRegex))
 	fflag.Var(&opt.IgnoreCase, 'i', "ignore-case", "ignore case distinctions in patterns and data",
 		fflag.InMutex("case"))
 	fflag.Var(&opt.NoIgnoreCase, fflag.NoShort, "no-ignore-case", "do not ignore case distinctions (default)",
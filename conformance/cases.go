@@ -0,0 +1,263 @@
+package conformance
+
+// The standard cases, taken from the POSIX "Utility Syntax
+// Guidelines", the POSIX `getopt()` specification, and the glibc
+// manual's description of `getopt()` and `getopt_long()`, in that
+// order.
+var Cases = []Case{
+	// POSIX
+	{
+		Name:   "separate flags",
+		Spec:   "a b",
+		Args:   []string{"-a", "-b"},
+		Events: []Event{E("a"), E("b")},
+	},
+	{
+		Name:   "cluster",
+		Spec:   "a b c",
+		Args:   []string{"-abc"},
+		Events: []Event{E("a"), E("b"), E("c")},
+	},
+	{
+		Name:   "required attached",
+		Spec:   "c:",
+		Args:   []string{"-carg"},
+		Events: []Event{E("c", "arg")},
+	},
+	{
+		Name:   "required detached",
+		Spec:   "c:",
+		Args:   []string{"-c", "arg"},
+		Events: []Event{E("c", "arg")},
+	},
+	{
+		Name:   "required ends cluster",
+		Spec:   "a c:",
+		Args:   []string{"-acarg"},
+		Events: []Event{E("a"), E("c", "arg")},
+	},
+	{
+		Name:   "required ends cluster detached",
+		Spec:   "a c:",
+		Args:   []string{"-ac", "arg"},
+		Events: []Event{E("a"), E("c", "arg")},
+	},
+	{
+		Name:   "required swallows flag letters",
+		Spec:   "a c:",
+		Args:   []string{"-caa"},
+		Events: []Event{E("c", "aa")},
+	},
+	{
+		Name:   "equals is part of short argument",
+		Spec:   "c:",
+		Args:   []string{"-c=arg"},
+		Events: []Event{E("c", "=arg")},
+	},
+	{
+		Name:   "equals is part of argument after cluster",
+		Spec:   "f g h:",
+		Args:   []string{"-fgh=arg"},
+		Events: []Event{E("f"), E("g"), E("h", "=arg")},
+	},
+	{
+		Name:  "required missing",
+		Spec:  "c:",
+		Args:  []string{"-c"},
+		Fails: true,
+	},
+	{
+		Name:  "undefined flag",
+		Spec:  "a",
+		Args:  []string{"-z"},
+		Fails: true,
+	},
+	{
+		Name:     "required takes hyphen",
+		Spec:     "a c:",
+		Args:     []string{"-c", "-a"},
+		Events:   []Event{E("c", "-a")},
+		Deviates: "a detached argument that looks like a flag is a flag",
+	},
+	{
+		Name:     "double hyphen ends flags",
+		Spec:     "a",
+		Args:     []string{"--", "-a"},
+		Operands: []string{"-a"},
+	},
+	{
+		Name:     "double hyphen as required argument",
+		Spec:     "c:",
+		Args:     []string{"-c", "--", "x"},
+		Events:   []Event{E("c", "--")},
+		Operands: []string{"x"},
+		Dialects: []string{"posix", "posix-detached"},
+	},
+	{
+		Name:     "double hyphen as required argument",
+		Spec:     "c:",
+		Args:     []string{"-c", "--", "x"},
+		Events:   []Event{E("c", "--")},
+		Operands: []string{"x"},
+		Dialects: []string{"gnu", "gnu-detached"},
+		Deviates: "the GNU preset always stops at a double hyphen",
+	},
+	{
+		Name:     "operand stops flags",
+		Spec:     "a",
+		Args:     []string{"x", "-a"},
+		Operands: []string{"x", "-a"},
+		Dialects: []string{"posix", "posix-detached"},
+	},
+	{
+		Name:     "single hyphen is an operand",
+		Spec:     "a",
+		Args:     []string{"-", "-a"},
+		Operands: []string{"-", "-a"},
+		Dialects: []string{"posix", "posix-detached"},
+	},
+	// glibc
+	{
+		Name:     "operands are permuted",
+		Spec:     "a c:",
+		Args:     []string{"x", "-a", "y", "-c", "z", "w"},
+		Events:   []Event{E("a"), E("c", "z")},
+		Operands: []string{"x", "y", "w"},
+		Dialects: []string{"gnu", "gnu-detached"},
+	},
+	{
+		Name:     "single hyphen is a permuted operand",
+		Spec:     "a",
+		Args:     []string{"-", "-a"},
+		Events:   []Event{E("a")},
+		Operands: []string{"-"},
+		Dialects: []string{"gnu", "gnu-detached"},
+	},
+	{
+		Name:     "double hyphen ends permutation",
+		Spec:     "a",
+		Args:     []string{"x", "--", "-a"},
+		Operands: []string{"x", "-a"},
+		Dialects: []string{"gnu", "gnu-detached"},
+	},
+	{
+		Name:   "optional attached",
+		Spec:   "d::",
+		Args:   []string{"-darg"},
+		Events: []Event{E("d", "arg")},
+	},
+	{
+		Name:     "optional detached is an operand",
+		Spec:     "d::",
+		Args:     []string{"-d", "arg"},
+		Events:   []Event{E("d")},
+		Operands: []string{"arg"},
+		Dialects: []string{"posix", "gnu"},
+	},
+	{
+		Name:     "optional detached",
+		Spec:     "d::",
+		Args:     []string{"-d", "arg"},
+		Events:   []Event{E("d", "arg")},
+		Dialects: []string{"posix-detached", "gnu-detached"},
+	},
+	{
+		Name:   "optional detached before flag",
+		Spec:   "a d::",
+		Args:   []string{"-d", "-a"},
+		Events: []Event{E("d"), E("a")},
+	},
+	{
+		Name:   "long",
+		Spec:   "|flag",
+		Args:   []string{"--flag"},
+		Events: []Event{E("flag")},
+	},
+	{
+		Name:   "short and long are the same option",
+		Spec:   "v|verbose",
+		Args:   []string{"-v", "--verbose"},
+		Events: []Event{E("verbose"), E("verbose")},
+	},
+	{
+		Name:   "long abbreviated",
+		Spec:   "|verbose |version",
+		Args:   []string{"--verb"},
+		Events: []Event{E("verbose")},
+	},
+	{
+		Name:  "long abbreviation ambiguous",
+		Spec:  "|verbose |version",
+		Args:  []string{"--ver"},
+		Fails: true,
+	},
+	{
+		Name:   "long exact match beats abbreviation",
+		Spec:   "|in |inode",
+		Args:   []string{"--in"},
+		Events: []Event{E("in")},
+	},
+	{
+		Name:  "long undefined",
+		Spec:  "|flag",
+		Args:  []string{"--flat"},
+		Fails: true,
+	},
+	{
+		Name:  "long no argument given one",
+		Spec:  "|flag",
+		Args:  []string{"--flag=x"},
+		Fails: true,
+	},
+	{
+		Name:   "long required with equals",
+		Spec:   "|file:",
+		Args:   []string{"--file=x"},
+		Events: []Event{E("file", "x")},
+	},
+	{
+		Name:   "long required empty",
+		Spec:   "|file:",
+		Args:   []string{"--file="},
+		Events: []Event{E("file", "")},
+	},
+	{
+		Name:   "long required detached",
+		Spec:   "|file:",
+		Args:   []string{"--file", "x"},
+		Events: []Event{E("file", "x")},
+	},
+	{
+		Name:  "long required missing",
+		Spec:  "|file:",
+		Args:  []string{"--file"},
+		Fails: true,
+	},
+	{
+		Name:   "long optional with equals",
+		Spec:   "|color::",
+		Args:   []string{"--color=always"},
+		Events: []Event{E("color", "always")},
+	},
+	{
+		Name:     "long optional detached is an operand",
+		Spec:     "|color::",
+		Args:     []string{"--color", "always"},
+		Events:   []Event{E("color")},
+		Operands: []string{"always"},
+		Dialects: []string{"posix", "gnu"},
+	},
+	{
+		Name:     "long optional detached",
+		Spec:     "|color::",
+		Args:     []string{"--color", "always"},
+		Events:   []Event{E("color", "always")},
+		Dialects: []string{"posix-detached", "gnu-detached"},
+	},
+	{
+		Name:   "long optional before flag",
+		Spec:   "a |color::",
+		Args:   []string{"--color", "-a"},
+		Events: []Event{E("color"), E("a")},
+	},
+}
@@ -0,0 +1,254 @@
+// Package `conformance` checks `fflag`'s argument processing against
+// tables of getopt-style option specs and argument vectors, with the
+// option events and operands that POSIX `getopt()` and glibc
+// `getopt_long()` are documented to produce for them (see `Cases`).
+// Each case is run against every dialect preset it applies to (see
+// `Presets`).
+//
+// The tables can be extended, or replaced, with cases for a
+// particular tool:
+//
+//	func TestConformance(t *testing.T) {
+//	    conformance.Run(t, append(conformance.Cases, myCases...))
+//	}
+//
+// A tool with a dialect of its own adds it to `Presets`, so that the
+// cases are run under it as well:
+//
+//	conformance.Presets["mytool"] = myDialect
+package conformance
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/EmmetCaulfield/fflag"
+)
+
+// An `ArgKind` says whether an option takes an option-argument, like
+// the `has_arg` field of glibc's `struct option`.
+type ArgKind int
+
+const (
+	NoArgument ArgKind = iota
+	RequiredArgument
+	OptionalArgument
+)
+
+// An `Option` describes an option with a short name, a long name, or
+// both.
+type Option struct {
+	Short rune
+	Long  string
+	Arg   ArgKind
+}
+
+// Function `Spec()` builds a list of options from a compact spec, in
+// which space-separated options are given as a short name, a long name
+// after a vertical bar, or both, followed by `:` for a required or
+// `::` for an optional option-argument, as in `getopt()`'s
+// `optstring`. For example, `a c: v|verbose |color::`.
+func Spec(spec string) []Option {
+	opts := []Option{}
+	for _, word := range strings.Fields(spec) {
+		opt := Option{Short: fflag.NoShort, Long: fflag.NoLong}
+		switch {
+		case strings.HasSuffix(word, "::"):
+			opt.Arg = OptionalArgument
+			word = strings.TrimSuffix(word, "::")
+		case strings.HasSuffix(word, ":"):
+			opt.Arg = RequiredArgument
+			word = strings.TrimSuffix(word, ":")
+		}
+		short, long, _ := strings.Cut(word, "|")
+		if short != "" {
+			opt.Short, _ = fflag.FirstRune(short)
+		}
+		if long != "" {
+			opt.Long = long
+		}
+		opts = append(opts, opt)
+	}
+	return opts
+}
+
+func (o Option) name() string {
+	if o.Long != fflag.NoLong {
+		return o.Long
+	}
+	return string(o.Short)
+}
+
+// An `Event` is an option being processed, named by its long name if
+// it has one and its short name otherwise, with its option-argument,
+// if any, as `getopt_long()` would return it.
+type Event struct {
+	Option string
+	Arg    string
+	HasArg bool
+}
+
+func (e Event) String() string {
+	if e.HasArg {
+		return fmt.Sprintf("%s(%s)", e.Option, e.Arg)
+	}
+	return e.Option + "()"
+}
+
+// Function `E()` is shorthand for an event, with an option-argument if
+// one is given.
+func E(option string, arg ...string) Event {
+	if len(arg) > 0 {
+		return Event{option, arg[0], true}
+	}
+	return Event{Option: option}
+}
+
+// A `Case` is an argument vector processed with a spec (see `Spec()`)
+// and the events and operands expected, or, if `Fails` is true, that
+// processing reports an error. It applies to the named `Presets`, or
+// all of them if `Dialects` is empty. If `Deviates` is given, the case
+// documents getopt behaviour that `fflag` deliberately doesn't follow,
+// for the reason given, and is skipped.
+type Case struct {
+	Name     string
+	Spec     string
+	Args     []string
+	Events   []Event
+	Operands []string
+	Fails    bool
+	Dialects []string
+	Deviates string
+}
+
+// The dialect presets against which cases are run, by name. Besides
+// POSIX and GNU, there are variants of each in which an optional
+// option-argument may be detached, as in earlier versions of `fflag`.
+// A tool with a dialect of its own can add it before calling `Run()`.
+var Presets = map[string]fflag.Dialect{
+	"posix":          fflag.PosixDialect,
+	"gnu":            fflag.GnuDialect,
+	"posix-detached": detached(fflag.PosixDialect),
+	"gnu-detached":   detached(fflag.GnuDialect),
+}
+
+// Function `detached()` returns a dialect like `d`, but in which an
+// optional option-argument may be detached.
+func detached(d fflag.Dialect) fflag.Dialect {
+	d.OptionalDetachedShort = true
+	d.OptionalDetachedLong = true
+	return d
+}
+
+// The value given to options with an optional option-argument when it
+// is absent, which can't appear in a real argument vector
+const noArg = "\x00"
+
+// A `recorder` is bound to each option and appends an event whenever
+// it is set.
+type recorder struct {
+	opt    Option
+	events *[]Event
+}
+
+func (r *recorder) Set(s string) error {
+	switch {
+	case r.opt.Arg == NoArgument && s != "true":
+		return fmt.Errorf("'%s' doesn't allow an argument", r.opt.name())
+	case r.opt.Arg == NoArgument || s == noArg:
+		*r.events = append(*r.events, E(r.opt.name()))
+	default:
+		*r.events = append(*r.events, E(r.opt.name(), s))
+	}
+	return nil
+}
+
+func (r *recorder) String() string {
+	return ""
+}
+
+func (r *recorder) IsBoolFlag() bool {
+	return r.opt.Arg == NoArgument
+}
+
+// Function `FlagSet()` builds a `FlagSet` having the options in a
+// spec, with the given dialect, that records events in `events` and
+// errors in `errs`.
+func FlagSet(spec string, d fflag.Dialect, events *[]Event, errs *bytes.Buffer) *fflag.FlagSet {
+	fs := fflag.NewFlagSet(fflag.WithDialect(d), fflag.WithContinueOnFail(),
+		fflag.WithOutputWriter(errs))
+	for _, opt := range Spec(spec) {
+		opts := []fflag.FlagOption{}
+		if opt.Arg == OptionalArgument {
+			opts = append(opts, fflag.WithOptionalDefault(noArg))
+		}
+		fs.Var(&recorder{opt, events}, opt.Short, opt.Long, opt.name(), opts...)
+	}
+	return fs
+}
+
+// Function `Check()` runs a case with a dialect and returns an error
+// describing any difference from what is expected.
+func Check(c Case, d fflag.Dialect) error {
+	events := []Event{}
+	errs := &bytes.Buffer{}
+	fs := FlagSet(c.Spec, d, &events, errs)
+	fs.Parse(c.Args)
+	if c.Fails {
+		if errs.Len() == 0 {
+			return fmt.Errorf("%q: expected an error, got %v", c.Args, events)
+		}
+		return nil
+	}
+	if errs.Len() > 0 {
+		return fmt.Errorf("%q: unexpected error: %s", c.Args, strings.TrimSpace(errs.String()))
+	}
+	expected := c.Events
+	if expected == nil {
+		expected = []Event{}
+	}
+	if !reflect.DeepEqual(expected, events) {
+		return fmt.Errorf("%q: expected events %v, got %v", c.Args, expected, events)
+	}
+	operands := []string(*fs.OutputArgs)
+	if c.Operands == nil {
+		c.Operands = []string{}
+	}
+	if !reflect.DeepEqual(c.Operands, operands) {
+		return fmt.Errorf("%q: expected operands %q, got %q", c.Args, c.Operands, operands)
+	}
+	return nil
+}
+
+// Function `Run()` runs each case as a subtest for each dialect preset
+// it applies to.
+func Run(t *testing.T, cases []Case) {
+	for _, c := range cases {
+		dialects := c.Dialects
+		if len(dialects) == 0 {
+			for name := range Presets {
+				dialects = append(dialects, name)
+			}
+			sort.Strings(dialects)
+		}
+		for _, name := range dialects {
+			c, name := c, name
+			t.Run(c.Name+"/"+name, func(t *testing.T) {
+				d, ok := Presets[name]
+				if !ok {
+					t.Fatalf("no dialect preset '%s'", name)
+				}
+				if c.Deviates != "" {
+					t.Skip(c.Deviates)
+				}
+				if err := Check(c, d); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
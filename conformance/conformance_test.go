@@ -0,0 +1,27 @@
+package conformance
+
+import (
+	"testing"
+)
+
+func TestConformance(t *testing.T) {
+	Run(t, Cases)
+}
+
+func TestSpec(t *testing.T) {
+	opts := Spec("a c: v|verbose |color::")
+	expected := []Option{
+		{'a', "", NoArgument},
+		{'c', "", RequiredArgument},
+		{'v', "verbose", NoArgument},
+		{0, "color", OptionalArgument},
+	}
+	if len(opts) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, opts)
+	}
+	for i := range opts {
+		if opts[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], opts[i])
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package fflag
+
+// A `Dialect` holds the rules that vary between POSIX and GNU argument
+// processing and that are otherwise taken from the package-level
+// `PosixEquals`, `PosixDoubleHyphen`, `PosixOperandStop`,
+// `OptionalDetachedShort`, and `OptionalDetachedLong` variables.
+// Giving a `FlagSet` its own `Dialect` with `WithDialect()` isolates
+// it from changes to those variables, so that `FlagSet`s with
+// different rules can be parsed concurrently.
+type Dialect struct {
+	// See `PosixEquals`
+	Equals bool
+	// See `PosixDoubleHyphen`
+	DoubleHyphen bool
+	// See `PosixOperandStop`
+	OperandStop bool
+	// See `OptionalDetachedShort`
+	OptionalDetachedShort bool
+	// See `OptionalDetachedLong`
+	OptionalDetachedLong bool
+}
+
+// The rules mandated by POSIX (which are also the package defaults)
+var PosixDialect = Dialect{
+	Equals:       true,
+	DoubleHyphen: true,
+	OperandStop:  true,
+}
+
+// The rules followed by GNU `getopt_long()`, which allow flags and
+// operands to be mixed and always stop at a double-hyphen, but, like
+// POSIX, only take an optional option-argument if it is attached
+var GnuDialect = Dialect{
+	Equals:       true,
+	DoubleHyphen: false,
+	OperandStop:  false,
+}
+
+// Function `CurrentDialect()` returns a `Dialect` having the current
+// values of the package-level `Posix*` and `OptionalDetached*`
+// variables.
+func CurrentDialect() Dialect {
+	return Dialect{
+		Equals:                PosixEquals,
+		DoubleHyphen:          PosixDoubleHyphen,
+		OperandStop:           PosixOperandStop,
+		OptionalDetachedShort: OptionalDetachedShort,
+		OptionalDetachedLong:  OptionalDetachedLong,
+	}
+}
+
+// Function `optionalDetached()` reports whether a flag with an
+// optional option-argument (see `WithOptionalDefault()`), given in the
+// form described by `argType`, takes the next argument as its
+// option-argument.
+func (d Dialect) optionalDetached(argType ArgMask) bool {
+	if argType.IsLongFlag() {
+		return d.OptionalDetachedLong
+	}
+	return d.OptionalDetachedShort
+}
+
+// Option `WithDialect()` gives a `FlagSet` its own argument-processing
+// rules instead of those in the package-level variables.
+func WithDialect(d Dialect) FlagSetOption {
+	return func(fs *FlagSet) {
+		fs.Dialect = &d
+	}
+}
+
+// Function `dialect()` returns the rules to be used for a parse: the
+// `FlagSet`'s own `Dialect`, if it has one, otherwise a snapshot of
+// the package-level variables.
+func (fs *FlagSet) dialect() Dialect {
+	if fs.Dialect != nil {
+		return *fs.Dialect
+	}
+	return CurrentDialect()
+}
@@ -0,0 +1,169 @@
+package fflag
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/EmmetCaulfield/fflag/pkg/trie"
+	"github.com/EmmetCaulfield/fflag/pkg/types"
+)
+
+// Option `WithAbbreviations()` lets the values in a flag's default
+// slice (see `WithDefault()` and `WithOptionalDefault()`), and any
+// aliases for them, be abbreviated to any unique prefix, just as long
+// flags can be, so that `--color=al` means `--color=always` if no
+// other value begins with `al`. An exact match always wins and an
+// ambiguous prefix is an error listing the candidates. It must come
+// after the default.
+func WithAbbreviations() FlagOption {
+	return func(f *Flag) error {
+		f.requireEnum("WithAbbreviations()")
+		f.Type.SetAbbrevBit()
+		return nil
+	}
+}
+
+// Option `WithFoldCase()` makes the values in a flag's default slice,
+// and any aliases for them, match case-insensitively, so that
+// `--color=Always` means `--color=always`. It must come after the
+// default.
+func WithFoldCase() FlagOption {
+	return func(f *Flag) error {
+		f.requireEnum("WithFoldCase()")
+		f.Type.SetFoldCaseBit()
+		return nil
+	}
+}
+
+// Option `WithValueAliases()` gives alternative names for values in a
+// flag's default slice, e.g. `{"yes": "always", "force": "always"}`,
+// which are accepted in their place. It must come after the default.
+func WithValueAliases(aliases map[string]string) FlagOption {
+	return func(f *Flag) error {
+		f.requireEnum("WithValueAliases()")
+		if f.EnumAliases == nil {
+			f.EnumAliases = map[string]string{}
+		}
+		for alias, value := range aliases {
+			if !f.inEnum(f.Default, value) {
+				log.Panicf("alias '%s' for '%s' is not a default value of '%s'", alias, value, f)
+			}
+			f.EnumAliases[alias] = value
+		}
+		return nil
+	}
+}
+
+func (f *Flag) requireEnum(option string) {
+	if !types.IsSlice(f.Default) || types.SliceLen(f.Default) < 1 {
+		log.Panicf("%s requires a default slice for '%s'", option, f)
+	}
+	if f.HasCallback() || types.IsSetter(f.Value) {
+		log.Panicf("%s makes no sense for '%s'", option, f)
+	}
+	f.enumKeys = nil
+	f.enumTrie = nil
+}
+
+// Function `enumKey()` folds the case of a value if required.
+func (f *Flag) enumKey(str string) string {
+	if f.Type.TstFoldCaseBit() {
+		return strings.ToLower(str)
+	}
+	return str
+}
+
+// Function `buildEnum()` maps the (possibly case-folded) string
+// values in the default slice, and their aliases, to the values they
+// stand for, and adds them to a trie for prefix matching if
+// abbreviations are allowed.
+func (f *Flag) buildEnum() {
+	f.enumKeys = map[string]string{}
+	for i := 0; i < types.SliceLen(f.Default); i++ {
+		if str, ok := types.ItemAt(f.Default, i).(string); ok {
+			f.addEnumKey(str, str)
+		}
+	}
+	for alias, value := range f.EnumAliases {
+		f.addEnumKey(alias, value)
+	}
+	if !f.Type.TstAbbrevBit() {
+		return
+	}
+	f.enumTrie = trie.NewTrie[string]()
+	for key := range f.enumKeys {
+		value := f.enumKeys[key]
+		if err := f.enumTrie.Add(key, &value); err != nil {
+			log.Panicf("failed to add '%s' to the values of '%s': %v", key, f, err)
+		}
+	}
+}
+
+func (f *Flag) addEnumKey(key, value string) {
+	key = f.enumKey(key)
+	if prev, ok := f.enumKeys[key]; ok && prev != value {
+		log.Panicf("'%s' stands for both '%s' and '%s' in '%s'", key, prev, value, f)
+	}
+	f.enumKeys[key] = value
+}
+
+// Function `resolveEnum()` returns the default value that the given
+// value stands for if abbreviations, case folding, or aliases are
+// enabled, or the value itself if it doesn't stand for one. It's an
+// error if an abbreviation stands for more than one.
+func (f *Flag) resolveEnum(str string) (string, error) {
+	if !f.Type.TstAbbrevBit() && !f.Type.TstFoldCaseBit() && len(f.EnumAliases) == 0 {
+		return str, nil
+	}
+	if f.enumKeys == nil {
+		f.buildEnum()
+	}
+	key := f.enumKey(str)
+	if value, ok := f.enumKeys[key]; ok {
+		return value, nil
+	}
+	if f.enumTrie == nil || key == "" {
+		return str, nil
+	}
+	if value, _ := f.enumTrie.Get(key); value != nil {
+		return *value, nil
+	}
+	// Several keys begin with the prefix, but they might all stand
+	// for the same value
+	seen := map[string]struct{}{}
+	candidates := []string{}
+	for _, k := range f.enumTrie.Keys(key) {
+		value := f.enumKeys[k]
+		if _, ok := seen[value]; !ok {
+			seen[value] = struct{}{}
+			candidates = append(candidates, value)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return str, nil
+	case 1:
+		return candidates[0], nil
+	}
+	sort.Strings(candidates)
+	return str, fmt.Errorf("ambiguous, could be %s", strings.Join(candidates, ", "))
+}
+
+// Function `resolveItems()` resolves each item of a list optarg to a
+// slice-valued flag, or the whole optarg otherwise (see
+// `resolveEnum()`).
+func (f *Flag) resolveItems(str string) (string, error) {
+	if f.IsScalar() {
+		return f.resolveEnum(str)
+	}
+	items := strings.Split(str, f.ListSeparator)
+	for i, item := range items {
+		var err error
+		if items[i], err = f.resolveEnum(item); err != nil {
+			return str, err
+		}
+	}
+	return strings.Join(items, f.ListSeparator), nil
+}
@@ -0,0 +1,226 @@
+// Package `fflagtest` provides utilities for testing command-line
+// tools built on `fflag`: an isolated `FlagSet` with its output
+// captured, parsing that turns an exit on failure into a result that
+// can be checked, assertions on parsed values and operands, and
+// comparison of help/usage text with golden files.
+//
+//	func TestTool(t *testing.T) {
+//	    h := fflagtest.New(t)
+//	    var verbose bool
+//	    h.FlagSet.Var(&verbose, 'v', "verbose", "talk more")
+//	    h.ParseOK("-v", "file")
+//	    h.AssertValue("verbose", true)
+//	    h.AssertOperands("file")
+//	    h.AssertUsage("usage")
+//	}
+//
+// Golden files are kept in `testdata/` and are rewritten, rather than
+// compared, when the tests are run with `UPDATE_GOLDEN` set in the
+// environment:
+//
+//	UPDATE_GOLDEN=1 go test ./...
+package fflagtest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/EmmetCaulfield/fflag"
+)
+
+// `UpdateEnv` is the environment variable that, when set to anything
+// but an empty string, makes `Golden()` rewrite golden files rather
+// than compare with them. An environment variable, rather than a
+// flag, so that it can't clash with a test's own flags.
+const UpdateEnv = "UPDATE_GOLDEN"
+
+// A `Harness` holds a `FlagSet` whose errors are recorded, whose
+// output and usage text are captured, and whose exits are turned
+// into a `Result` by `Parse()`.
+type Harness struct {
+	T       testing.TB
+	FlagSet *fflag.FlagSet
+	Output  *bytes.Buffer
+	Help    *bytes.Buffer
+	errors  []string
+}
+
+// An `exit` is raised by the harness's exit function to unwind from
+// the failure to `Parse()`.
+type exit struct {
+	code int
+}
+
+// Function `New()` creates a `Harness` with a new `FlagSet` having the
+// given options, except for a `Reporter`, exit function, or writers,
+// which the harness provides.
+func New(t testing.TB, opts ...fflag.FlagSetOption) *Harness {
+	h := &Harness{T: t, Output: &bytes.Buffer{}, Help: &bytes.Buffer{}}
+	h.FlagSet = fflag.NewFlagSet(append(opts,
+		fflag.WithReporter(&recorder{h, &fflag.WriterReporter{Output: h.Output, HelpOutput: h.Help}}),
+		fflag.WithExitFunc(func(code int) { panic(exit{code}) }))...)
+	return h
+}
+
+// A `recorder` records errors and passes everything on to a
+// `WriterReporter`.
+type recorder struct {
+	h *Harness
+	*fflag.WriterReporter
+}
+
+func (r *recorder) Error(category fflag.ErrorCategory, msg string) {
+	r.h.errors = append(r.h.errors, msg)
+	r.WriterReporter.Error(category, msg)
+}
+
+// Function `Isolate()` saves the default `FlagSet` and the
+// package-level dialect variables, replacing the former with the
+// given `FlagSet`, and restores them all when the test ends, so that
+// tests of code using the default `FlagSet` don't interfere with
+// each other.
+func Isolate(t testing.TB, fs *fflag.FlagSet) {
+	commandLine := fflag.CommandLine
+	dialect := fflag.CurrentDialect()
+	t.Cleanup(func() {
+		fflag.CommandLine = commandLine
+		fflag.PosixEquals = dialect.Equals
+		fflag.PosixDoubleHyphen = dialect.DoubleHyphen
+		fflag.PosixOperandStop = dialect.OperandStop
+		fflag.OptionalDetachedShort = dialect.OptionalDetachedShort
+		fflag.OptionalDetachedLong = dialect.OptionalDetachedLong
+	})
+	fflag.CommandLine = fs
+}
+
+// A `Result` describes the outcome of parsing with a `Harness`.
+type Result struct {
+	// Whether the `FlagSet` would have exited
+	Exited bool
+	// The exit code it would have exited with
+	Code int
+	// The errors reported, including any it exited after
+	Errors []string
+	// Everything written to the `FlagSet`'s output while parsing
+	Output string
+}
+
+func (r Result) String() string {
+	if r.Exited {
+		return fmt.Sprintf("exit %d: %s", r.Code, strings.Join(r.Errors, "; "))
+	}
+	return strings.Join(r.Errors, "; ")
+}
+
+// Function `Parse()` parses the arguments with the harness's
+// `FlagSet` and returns the outcome, recovering an exit on failure.
+func (h *Harness) Parse(args ...string) (r Result) {
+	h.T.Helper()
+	start := h.Output.Len()
+	h.errors = nil
+	defer func() {
+		r.Output = h.Output.String()[start:]
+		r.Errors = h.errors
+		if p := recover(); p != nil {
+			e, ok := p.(exit)
+			if !ok {
+				panic(p)
+			}
+			r.Exited = true
+			r.Code = e.code
+		}
+	}()
+	h.FlagSet.Parse(args)
+	return r
+}
+
+// Function `ParseOK()` parses the arguments and fails the test if
+// there is an error.
+func (h *Harness) ParseOK(args ...string) Result {
+	h.T.Helper()
+	r := h.Parse(args...)
+	if r.Exited || len(r.Errors) > 0 {
+		h.T.Fatalf("%q: unexpected failure: %s", args, r)
+	}
+	return r
+}
+
+// Function `ParseExits()` parses the arguments and fails the test
+// unless the `FlagSet` would have exited with the given code.
+func (h *Harness) ParseExits(code int, args ...string) Result {
+	h.T.Helper()
+	r := h.Parse(args...)
+	if !r.Exited {
+		h.T.Fatalf("%q: expected exit %d, got none", args, code)
+	}
+	if r.Code != code {
+		h.T.Errorf("%q: expected exit %d, got %s", args, code, r)
+	}
+	return r
+}
+
+// Function `AssertValue()` checks the value of the flag with the given
+// long name or short flag (a rune), or of the flag it is an alias for.
+func (h *Harness) AssertValue(name interface{}, want interface{}) {
+	h.T.Helper()
+	f := h.FlagSet.Lookup(name)
+	if f == nil {
+		h.T.Errorf("flag '%v' not defined", name)
+		return
+	}
+	got := h.FlagSet.Get(name)
+	if !reflect.DeepEqual(got, want) {
+		h.T.Errorf("flag '%s': expected %#v, got %#v", f, want, got)
+	}
+}
+
+// Function `AssertOperands()` checks the operands left after parsing.
+func (h *Harness) AssertOperands(want ...string) {
+	h.T.Helper()
+	got := []string(*h.FlagSet.OutputArgs)
+	if len(got) == 0 && len(want) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		h.T.Errorf("expected operands %q, got %q", want, got)
+	}
+}
+
+// Function `AssertUsage()` compares the usage text printed by the
+// harness's `FlagSet` with a golden file (see `Golden()`).
+func (h *Harness) AssertUsage(name string) {
+	h.T.Helper()
+	h.Help.Reset()
+	h.FlagSet.DumpUsage()
+	Golden(h.T, name, h.Help.String())
+}
+
+// Function `Golden()` compares text, such as help, man page, or
+// completion output, with the golden file `testdata/<name>.golden`,
+// or writes the file if `UPDATE_GOLDEN` is set (see `UpdateEnv`).
+func Golden(t testing.TB, name string, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with %s=1 to create it)", err, UpdateEnv)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s (run with %s=1 to accept it)\n--- want\n%s--- got\n%s",
+			path, UpdateEnv, want, got)
+	}
+}
@@ -0,0 +1,93 @@
+package fflagtest
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/EmmetCaulfield/fflag"
+)
+
+func harness(t *testing.T) *Harness {
+	h := New(t, fflag.WithDialect(fflag.GnuDialect))
+	var verbose int
+	var name string
+	var sizes []int
+	h.FlagSet.Var(&verbose, 'v', "verbose", "talk more", fflag.AsCounter())
+	h.FlagSet.Var(&name, 'n', "name", "who to greet", fflag.WithDefault("world"))
+	h.FlagSet.Var(&sizes, 's', "size", "sizes to try")
+	return h
+}
+
+func TestHarness(t *testing.T) {
+	h := harness(t)
+	h.ParseOK("-vv", "file", "--name=you", "-s", "1,2", "--", "-v")
+	h.AssertValue("verbose", 2)
+	h.AssertValue('n', "you")
+	h.AssertValue("size", []int{1, 2})
+	h.AssertOperands("file", "-v")
+	h.AssertUsage("usage")
+
+	h = harness(t)
+	r := h.ParseExits(fflag.DefaultFailExitCode, "-x")
+	if !strings.Contains(r.Output, "ERROR") || len(r.Errors) != 1 ||
+		!strings.Contains(r.Errors[0], "'x'") {
+		t.Errorf("unexpected result %+v", r)
+	}
+	h.AssertOperands()
+}
+
+func TestAliasValue(t *testing.T) {
+	h := New(t)
+	var n int
+	h.FlagSet.Var(&n, 'n', "number", "a number", fflag.WithAlias('N', "num", false))
+	h.ParseOK("-N", "5")
+	h.AssertValue("num", 5)
+	h.AssertValue('N', 5)
+	h.AssertValue("number", 5)
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(dir)
+	t.Setenv(UpdateEnv, "1")
+	Golden(t, "new", "text\n")
+	t.Setenv(UpdateEnv, "")
+	Golden(t, "new", "text\n")
+}
+
+func TestFileError(t *testing.T) {
+	h := New(t)
+	h.FlagSet.FileErrExitCode = 3
+	var lines []string
+	h.FlagSet.Var(&lines, 'f', "file", "read lines from a file", fflag.ReadFile())
+	h.ParseExits(3, "-f", "testdata/no-such-file")
+
+	h = New(t, fflag.WithContinueOnFail())
+	h.FlagSet.Var(&lines, 'f', "file", "read lines from a file", fflag.ReadFile())
+	r := h.Parse("-f", "testdata/no-such-file")
+	if r.Exited || len(r.Errors) == 0 {
+		t.Errorf("unexpected result %+v", r)
+	}
+}
+
+func TestIsolate(t *testing.T) {
+	commandLine := fflag.CommandLine
+	t.Run("isolated", func(t *testing.T) {
+		h := harness(t)
+		Isolate(t, h.FlagSet)
+		fflag.PosixOperandStop = !fflag.PosixOperandStop
+		if fflag.CommandLine != h.FlagSet {
+			t.Errorf("default FlagSet not replaced")
+		}
+	})
+	if fflag.CommandLine != commandLine || !fflag.PosixOperandStop {
+		t.Errorf("default FlagSet and dialect not restored")
+	}
+}
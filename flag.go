@@ -62,8 +62,9 @@
 // The first argument to `Var` must be a _pointer_ to one of:
 //
 //   1) a basic datatype (e.g. `int8`, `float32`, `string`)
-//   2) a slice of basic datatype (e.g. `[]int8`, `[]string`)
-//   3) something implementing the `SetValue` interface
+//   2) a slice or array of basic datatype (e.g. `[]int8`, `[2]string`)
+//   3) a map from strings to a basic datatype (e.g. `map[string]int`)
+//   4) something implementing the `SetValue` interface
 //
 // Non-pointer `value` arguments will cause a `panic()`. As a rule,
 // `fflag` will `panic()` in the case of a programmer mistake (during
@@ -95,9 +96,11 @@
 // There is a special case (and common idiom) where NEITHER a long NOR
 // a short form is required: `-NUM` (as in `grep`, `head`, `tail`, and
 // several other tools). These special cases are always an alias for
-// something else and always refer to a natural number appearing after
-// a single hyphen. For example `head`'s `-n/--lines` is best
-// represented as:
+// something else and always refer to a number, which may be given to
+// a flag of any integer type, appearing after a single hyphen,
+// perhaps followed by a suffix (e.g. `-5K`, if the target takes
+// `WithIntUnits()`) or a cluster of short flags (e.g. `tail -5f`).
+// For example `head`'s `-n/--lines` is best represented as:
 //
 //     uint nlines
 //     fflag.Var(&nlines, 'n', 'lines',
@@ -108,6 +111,19 @@
 // to use it more than once is a programmer error that results in a
 // `panic()`.
 //
+// The `+NUM` idiom (e.g. `tail +5`) likewise targets a flag given
+// `WithPlusNum()`, which can differ from the target of `-NUM`. A flag
+// given `WithNegativeArgs()` takes a negative number given as a
+// separate argument, despite it looking like a flag (e.g. `head -c
+// -20`).
+//
+// A `FlagSet` can also accept long flags after a single hyphen (e.g.
+// `-name`, see `WithSingleDashLongs()`) or after `-W` (e.g. `-W
+// name=x`, see `WithLongW()`), and flags turned off with a plus (e.g.
+// `set +x`, see `WithPlusOptions()` and `PlusNegates()`). Flags added
+// to an operand group (see `NewOperandGroup()`) are given as `key=value`
+// operands instead, as in `dd if=FILE bs=4K`.
+//
 // The simplest “vanilla” flag is a nullary boolean switch that takes
 // no parameter.
 //
@@ -268,6 +284,68 @@
 // value changes to that argument _provided that_ it is in the default
 // slice. If `--color=foo` were given, it would result in an error.
 //
+// As with GNU `grep`, the values can be abbreviated to a unique prefix
+// (e.g. `--color=al`) with `WithAbbreviations()`, matched regardless
+// of case with `WithFoldCase()`, and given other names (e.g. `yes`
+// for `always`) with `WithValueAliases()`.
+//
+// Besides the basic types, a value may be (a pointer to, or slice of)
+// a `time.Duration`, a `time.Time`, a `types.ByteSize` (e.g. `10K`),
+// a `net.IP`, a `netip.Addr`, `netip.Prefix`, or `netip.AddrPort`, a
+// `types.HostPort`, or a `url.URL`. Addresses can be constrained with
+// `WithAddrFamily()` and `WithNetworks()`, and URLs with
+// `WithSchemes()`:
+//
+//     var proxy url.URL
+//     fflag.Var(&proxy, fflag.NoShort, "proxy", "use a proxy",
+//         fflag.WithSchemes("http", "https", "socks5"))
+//
+// Integers can be given as Go/C literals in other bases (e.g.
+// `--mask=0x1ff`, or `--mode=0755` as octal) with `WithIntLiterals()`
+// and with multiplicative suffixes (e.g. `--count=10K`) with
+// `WithIntUnits()`.
+//
+// Values can be checked after conversion with `WithRange()`,
+// `WithRangeStep()`, `WithPattern()`, and `WithValidator()`, which
+// compose and apply to each element of a slice or map:
+//
+//     var port uint16
+//     fflag.Var(&port, 'p', "port", "listen on PORT",
+//         fflag.WithRange(1024, 65535))
+//
+// An option-argument that can't be converted or is rejected results
+// in a `*ValueError` naming the flag.
+//
+// A map-valued flag takes `key=value` pairs, one or more per
+// option-argument, and is implicitly repeatable, like a slice:
+//
+//     labels := map[string]string{}
+//     fflag.Var(&labels, 'L', "label", "add a label",
+//         fflag.WithKeys("env", "team"), fflag.WithDupKeys(types.DupKeysError))
+//
+// so that `-L env=prod -L team=infra` or `--label env=prod,team=infra`
+// sets both labels, but `-L env=prod -L env=dev` is an error.
+//
+// A flag whose value is a set of members of an enum, such as
+// `--debug=parse,io`, is made with `AsSet()` and bound to a
+// `[]string`, a `map[string]bool`, or an integer with a bit for each
+// member:
+//
+//     var debug uint
+//     fflag.Var(&debug, 'D', "debug", "debug the given subsystems",
+//         fflag.AsSet("parse", "io", "net"), fflag.WithDefault("parse"))
+//
+// Here, `--debug=io,net` replaces the set, `--debug=+io,-parse`
+// edits it, and `--debug=all,-net` uses the `all` keyword (`none` is
+// the other). Each item of a list optarg to an ordinary slice-valued
+// flag is likewise checked against a default slice, if any.
+//
+// The generic `Add()` is an alternative to `Var()` whose options are
+// checked against the type of the value at compile time (e.g.
+// `fflag.Default(3)` for an `int`) and which accepts any type for
+// which a parser and formatter have been registered with `Register()`,
+// without the type having to implement `SetValue`.
+//
 // ## Package Options
 //
 //
@@ -277,11 +355,17 @@ package fflag
 import (
 	"bufio"
 	"bytes"
+	"fmt"
 	"log"
+	"net"
+	"net/netip"
+	"net/url"
 	"os"
+	"reflect"
 	"strings"
 	"unicode"
 
+	"github.com/EmmetCaulfield/fflag/pkg/trie"
 	"github.com/EmmetCaulfield/fflag/pkg/types"
 )
 
@@ -289,13 +373,18 @@ import (
 // separator. This functionality is not well tested.
 var DefaultListSeparator string = ","
 
+// The separator between the key and value of a pair given to a map
+// flag (e.g. `-L env=prod`)
+var DefaultPairSeparator string = "="
+
 // POSIX uses '?' for a special purpose in `getopt()`, making it
 // unsuitable for use as an option, but some applications use it
 // explicitly, often for help, so we allow it, but reject it by
 // default.
 var PosixRejectQuest bool = true
 
-// POSIX reserves `-W` for vendor options
+// POSIX reserves `-W` for vendor options (see `WithLongW()` for the
+// GNU one)
 var PosixRejectW bool = true
 
 // The equals separator, if present, is regarded as part of the
@@ -315,6 +404,19 @@ var PosixDoubleHyphen bool = true
 // the operand list as they are encountered.
 var PosixOperandStop bool = true
 
+// POSIX requires an optional option-argument to be attached to a short
+// flag (`-ofoo`), so that in `-o foo`, `foo` is an operand. When set to
+// true, a flag with an optional default (see `WithOptionalDefault()`)
+// also takes the next argument, if it isn't a flag and the flag will
+// accept it, as many programs do.
+var OptionalDetachedShort bool = false
+
+// Likewise, GNU `getopt_long()` requires an optional option-argument
+// to be attached to a long flag (`--color=always`), so that in
+// `--color always`, `always` is an operand. When set to true, the
+// next argument is taken as for `OptionalDetachedShort`.
+var OptionalDetachedLong bool = false
+
 type FlagError struct {
 	s string
 }
@@ -323,9 +425,28 @@ func (fe *FlagError) Error() string {
 	return fe.s
 }
 
+// A `ValueError` is returned when an option-argument can't be
+// converted to the type of a flag's value or is rejected by one of
+// the flag's constraints.
+type ValueError struct {
+	Flag  *Flag
+	Value string
+	Err   error
+}
+
+func (ve *ValueError) Error() string {
+	return fmt.Sprintf("invalid value '%s' for flag '%s': %v", ve.Value, ve.Flag, ve.Err)
+}
+
+func (ve *ValueError) Unwrap() error {
+	return ve.Err
+}
+
 // If a CallbackFunction is supplied using the `WithCallback()` option
 // to `Var()`, it will be called when the corresponding flag appears
-// on the command-line.
+// on the command-line. It is called with the `FlagSet`'s lock held,
+// so it must not call the `FlagSet` methods that take the lock (see
+// `FlagSet`).
 type CallbackFunction func(f *Flag, arg string, pos int) error
 
 // FlagType is a bitmask whose bits indicate various properties of a
@@ -346,6 +467,10 @@ const (
 	FileBit           FlagType = 0b0000001000000000
 	DefOptionalBit    FlagType = 0b0000010000000000
 	SavedFileBit      FlagType = 0b0000100000000000
+	AbbrevBit         FlagType = 0b0001000000000000
+	FoldCaseBit       FlagType = 0b0010000000000000
+	PlusNumBit        FlagType = 0b0100000000000000
+	PlusNegatesBit    FlagType = 0b1000000000000000
 )
 
 func (ft *FlagType) TstLongAliasBit() bool      { return *ft&LongAliasBit != 0 }
@@ -360,6 +485,10 @@ func (ft *FlagType) TstIgnoreRepeatsBit() bool  { return *ft&IgnoreRepeatsBit !=
 func (ft *FlagType) TstFileBit() bool           { return *ft&FileBit != 0 }
 func (ft *FlagType) TstDefOptionalBit() bool    { return *ft&DefOptionalBit != 0 }
 func (ft *FlagType) TstSavedFileBit() bool      { return *ft&SavedFileBit != 0 }
+func (ft *FlagType) TstAbbrevBit() bool         { return *ft&AbbrevBit != 0 }
+func (ft *FlagType) TstFoldCaseBit() bool       { return *ft&FoldCaseBit != 0 }
+func (ft *FlagType) TstPlusNumBit() bool        { return *ft&PlusNumBit != 0 }
+func (ft *FlagType) TstPlusNegatesBit() bool    { return *ft&PlusNegatesBit != 0 }
 func (ft *FlagType) TstAliasBits() bool         { return (*ft&ShortAliasBit)|(*ft&LongAliasBit) != 0 }
 
 func (ft *FlagType) ClrLongAliasBit()      { *ft = *ft & ^LongAliasBit }
@@ -374,6 +503,10 @@ func (ft *FlagType) ClrIgnoreRepeatsBit()  { *ft = *ft & ^IgnoreRepeatsBit }
 func (ft *FlagType) ClrFileBit()           { *ft = *ft & ^FileBit }
 func (ft *FlagType) ClrDefOptionalBit()    { *ft = *ft & ^DefOptionalBit }
 func (ft *FlagType) ClrSavedFileBit()      { *ft = *ft & ^SavedFileBit }
+func (ft *FlagType) ClrAbbrevBit()         { *ft = *ft & ^AbbrevBit }
+func (ft *FlagType) ClrFoldCaseBit()       { *ft = *ft & ^FoldCaseBit }
+func (ft *FlagType) ClrPlusNumBit()        { *ft = *ft & ^PlusNumBit }
+func (ft *FlagType) ClrPlusNegatesBit()    { *ft = *ft & ^PlusNegatesBit }
 
 func (ft *FlagType) SetLongAliasBit()      { *ft = *ft | LongAliasBit }
 func (ft *FlagType) SetShortAliasBit()     { *ft = *ft | ShortAliasBit }
@@ -387,6 +520,10 @@ func (ft *FlagType) SetIgnoreRepeatsBit()  { *ft = *ft | IgnoreRepeatsBit }
 func (ft *FlagType) SetFileBit()           { *ft = *ft | FileBit }
 func (ft *FlagType) SetDefOptionalBit()    { *ft = *ft | DefOptionalBit }
 func (ft *FlagType) SetSavedFileBit()      { *ft = *ft | SavedFileBit }
+func (ft *FlagType) SetAbbrevBit()         { *ft = *ft | AbbrevBit }
+func (ft *FlagType) SetFoldCaseBit()       { *ft = *ft | FoldCaseBit }
+func (ft *FlagType) SetPlusNumBit()        { *ft = *ft | PlusNumBit }
+func (ft *FlagType) SetPlusNegatesBit()    { *ft = *ft | PlusNegatesBit }
 
 // A Flag represents a command-line flag, option, or switch.
 type Flag struct {
@@ -400,10 +537,37 @@ type Flag struct {
 	AliasFor      *Flag
 	Usage         string
 	Callback      CallbackFunction
+	UnsetCallback CallbackFunction
 	ListSeparator string
+	TimeLayouts   []string
+	AddrFamily    types.AddrFamily
+	Networks      []netip.Prefix
+	DefaultPort   uint16
+	Schemes       []string
+	PairSeparator string
+	DupKeys       types.DupKeyPolicy
+	Keys          []string
+	MinArgs       int
+	MaxArgs       int
+	Members       []string
+	EnumAliases   map[string]string
+	Validators    []Validator
+	IntLiterals   bool
+	OctalZero     bool
+	IntUnits      bool
+	NegativeArgs  bool
+	Ranges        []Range
 	Mutexes       map[string]struct{}
 	parentFlagSet *FlagSet
+	operand       bool
 	savedCallback CallbackFunction
+	initial       reflect.Value
+	initialBase   int
+	hasInitial    bool
+	seenKeys      map[string]struct{}
+	enumKeys      map[string]string
+	enumTrie      *trie.TrieNode[string]
+	intBase       int
 }
 
 // The ID separator separates the short version of a flag from the
@@ -543,7 +707,7 @@ func (f *Flag) MutexCollides() *Flag {
 	fs := f.ParentFlagSet()
 	for name, _ := range f.Mutexes {
 		if flag, ok := fs.Mutex[name]; ok {
-			if flag != nil {
+			if flag != nil && flag != f {
 				return flag
 			}
 			fs.Mutex[name] = f
@@ -609,22 +773,31 @@ func (f *Flag) Set(value interface{}, argPos int) error {
 func (f *Flag) testOrSet(value interface{}, argPos int, doSet bool) error {
 	prev := f.MutexCollides()
 	if prev != nil {
-		f.Failf("flag '%s' conflicts with previously given flag '%s'", f, prev)
-		return &FlagError{"mutex collision in Flag.Set()"}
+		return f.failf(true, &FlagError{"mutex collision in Flag.Set()"},
+			"flag '%s' conflicts with previously given flag '%s'", f, prev)
 	}
 	// Prefer the SetValue interface if present:
 	if setter, ok := f.Value.(types.SetValue); ok {
+		if value == nil {
+			if types.IsBoolSetter(setter) {
+				value = "true"
+			} else if f.Default != nil {
+				value = f.GetDefault()
+			}
+		}
 		if str, ok := value.(string); ok {
-			f.Count++
+			if !f.InDefaults(str) {
+				return f.failf(doSet, &FlagError{"value constrained by defaults"},
+					"value %v not found in defaults %v for '%s'", str, f.Default, f)
+			}
 			if doSet {
+				f.Count++
 				return setter.Set(str)
 			}
 			return nil
 		}
-		if doSet {
-			f.Failf("Cannot pass non-string to SetValue.Set(string) in flag.Set() for flag '%s'", f)
-		}
-		return &FlagError{"cannot pass non-string to SetValue.Set()"}
+		return f.failf(doSet, &FlagError{"cannot pass non-string to SetValue.Set()"},
+			"Cannot pass non-string to SetValue.Set(string) in flag.Set() for flag '%s'", f)
 	}
 
 	if f.AliasFor != nil {
@@ -648,25 +821,25 @@ func (f *Flag) testOrSet(value interface{}, argPos int, doSet bool) error {
 		//     panic("non-numeric value cannot be a counter")
 		// }
 		str := types.StrConv(f.Count)
-		err := f.testOrSetOnly(str, argPos, doSet)
-		if err != nil && doSet {
-			f.Failf("failed to set counter '%s' from %d: %v", f, f.Count, err)
+		// Tested first so that a failure is only reported once
+		err := f.testOrSetOnly(str, argPos, false)
+		if err != nil {
+			return f.failf(doSet, err, "failed to set counter '%s' from %d: %v", f, f.Count, err)
 		}
-		return err
+		return f.testOrSetOnly(str, argPos, doSet)
 	}
 
 	if f.IsFileReader() {
 		filename, ok := value.(string)
 		if !ok {
-			if doSet {
-				f.Failf("file-reader flag %s expects a filename (string) argument", f)
-			}
-			return &FlagError{"argument to flag-reader not a string"}
+			return f.failf(doSet, &FlagError{"argument to flag-reader not a string"},
+				"file-reader flag %s expects a filename (string) argument", f)
 		}
 		file, err := os.Open(filename)
 		if err != nil {
 			if doSet {
-				f.Failf("failed to open file '%s' for flag '%s': %v", filename, f, err)
+				f.FileFailf("failed to open file '%s' for flag '%s': %v", filename, f, err)
+				return &reportedError{err}
 			}
 			return err
 		}
@@ -680,22 +853,24 @@ func (f *Flag) testOrSet(value interface{}, argPos int, doSet bool) error {
 				if doSet {
 					err := f.Callback(f, line, lineNo)
 					if err != nil {
-						f.Failf("callback failed for '%s' in '%s': %v", line, f, err)
-						return err
+						return f.failf(true, err, "callback failed for '%s' in '%s': %v", line, f, err)
 					}
 				}
 				continue
 			}
-			err := f.testOrSetOnly(line, lineNo, doSet)
+			// Tested first so that a bad line is reported once, with
+			// its line number
+			err := f.testOrSetOnly(line, lineNo, false)
 			if err != nil {
-				if doSet {
-					f.Failf("failed to set '%s' from line %d in '%s': %v", f, lineNo, filename, err)
-				}
+				return f.failf(doSet, err, "failed to set '%s' from line %d in '%s': %v", f, lineNo, filename, err)
+			}
+			if err = f.testOrSetOnly(line, lineNo, doSet); err != nil {
 				return err
 			}
 			if err = scanner.Err(); err != nil {
 				if doSet {
-					f.Failf("error scanning '%s': %v", filename, err)
+					f.FileFailf("error scanning '%s': %v", filename, err)
+					return &reportedError{err}
 				}
 				return err
 			}
@@ -712,16 +887,23 @@ func (f *Flag) testOrSet(value interface{}, argPos int, doSet bool) error {
 	}
 
 	if f.Count > 1 && !f.IsRepeatable() {
-		if doSet {
-			f.Failf("flag '%s' is not repeatable", f.String())
-		}
-		return &FlagError{"flag not repeatable"}
+		return f.failf(doSet, &FlagError{"flag not repeatable"},
+			"flag '%s' is not repeatable", f.String())
 	}
 
 	if f.Count > 1 && f.IgnoreRepeats() {
 		return nil
 	}
 
+	if str, ok := value.(string); ok && !f.HasMembers() {
+		var err error
+		value, err = f.resolveItems(str)
+		if err != nil {
+			err = &ValueError{Flag: f, Value: str, Err: err}
+			return f.failf(doSet, err, "%v", err)
+		}
+	}
+
 	if value == nil {
 		if boolp, ok := f.Value.(*bool); ok {
 			// If a default was given, use it, otherwise the zero
@@ -735,25 +917,32 @@ func (f *Flag) testOrSet(value interface{}, argPos int, doSet bool) error {
 		}
 		value = f.GetDefault()
 		if value == nil {
-			if doSet {
-				f.Failf("flag.Set(nil) called for flag '%s' with no default", f)
-			}
-			return &FlagError{"cannot set nil value for non-bool with no default"}
-		}
-	} else if !f.InDefaults(value) {
-		// TODO(emmet): consider supporting constrained defaults in a
-		// command-line list. It's reasonable to expect that each item
-		// in a list optarg would be checked against the list in
-		// f.Default (if any), but in reality, a non-scalar optarg
-		// (e.g. `-x foo,bar,baz`) will fail here.
-		if doSet {
-			f.Failf("value %v not found in defaults %v for '%s'", value, f.Default, f)
+			return f.failf(doSet, &FlagError{"cannot set nil value for non-bool with no default"},
+				"flag.Set(nil) called for flag '%s' with no default", f)
 		}
-		return &FlagError{"value constrained by defaults"}
+	} else if !f.HasMembers() && !f.itemsInDefaults(value) {
+		return f.failf(doSet, &FlagError{"value constrained by defaults"},
+			"value %v not found in defaults %v for '%s'", value, f.Default, f)
 	}
 	return f.testOrSetOnly(value, argPos, doSet)
 }
 
+// Function `itemsInDefaults()` checks each item of a list optarg to
+// a slice-valued flag (e.g. `-x foo,bar,baz`) against the defaults, or
+// the whole value otherwise.
+func (f *Flag) itemsInDefaults(value interface{}) bool {
+	str, ok := value.(string)
+	if !ok || f.IsScalar() || !types.IsSlice(f.Default) {
+		return f.InDefaults(value)
+	}
+	for _, item := range strings.Split(str, f.ListSeparator) {
+		if !f.InDefaults(item) {
+			return false
+		}
+	}
+	return true
+}
+
 // Function `TestOnly()` silently tests if a flag's value can be set
 // to the given value, bypassing flag type logic.
 func (f *Flag) TestOnly(value interface{}, argPos int) error {
@@ -766,6 +955,111 @@ func (f *Flag) SetOnly(value interface{}, argPos int) error {
 	return f.testOrSetOnly(value, argPos, true)
 }
 
+// Function `SetArgs()` sets a flag taking several option-arguments
+// (see `WithNArgs()`) from the arguments given with one appearance of
+// the flag, which are appended to a slice or stored in successive
+// elements of an array. Every argument is checked before any is set,
+// so a failure leaves the value unchanged.
+func (f *Flag) SetArgs(args []string, argPos int) error {
+	if f.AliasFor != nil {
+		f = f.AliasFor
+	}
+	if !f.HasNArgs() {
+		return f.failf(true, &FlagError{"flag does not take several arguments"},
+			"flag '%s' does not take several arguments", f)
+	}
+	if len(args) < f.MinArgs || len(args) > f.MaxArgs {
+		err := &ValueError{Flag: f, Value: strings.Join(args, " "),
+			Err: fmt.Errorf("expected %s arguments, got %d", f.nargsString(), len(args))}
+		return f.failf(true, err, "%v", err)
+	}
+	prev := f.MutexCollides()
+	if prev != nil {
+		return f.failf(true, &FlagError{"mutex collision in Flag.SetArgs()"},
+			"flag '%s' conflicts with previously given flag '%s'", f, prev)
+	}
+	if f.Count > 0 && !f.IsRepeatable() {
+		return f.failf(true, &FlagError{"flag not repeatable"},
+			"flag '%s' is not repeatable", f.String())
+	}
+
+	base := 0
+	if !types.IsArray(f.Value) {
+		base = types.SliceLen(f.Value)
+	}
+	if !f.HasCallback() {
+		resolved := make([]string, len(args))
+		for i, arg := range args {
+			var err error
+			resolved[i], err = f.resolveEnum(arg)
+			if err != nil {
+				err = &ValueError{Flag: f, Value: arg, Err: err}
+				return f.failf(true, err, "%v", err)
+			}
+		}
+		args = resolved
+		for i, arg := range args {
+			err := types.SetItem(f.Value, base+i, arg, false, f.convOptions()...)
+			if err == nil && !f.InDefaults(arg) {
+				err = fmt.Errorf("not found in defaults %v", f.Default)
+			}
+			if err == nil {
+				err = f.validateItem(arg)
+			}
+			if err != nil {
+				err = &ValueError{Flag: f, Value: arg, Err: err}
+				return f.failf(true, err, "%v", err)
+			}
+		}
+	}
+
+	f.Count++
+	for i, arg := range args {
+		var err error
+		if f.HasCallback() {
+			err = f.Callback(f, arg, argPos+i)
+		} else {
+			var intBase int
+			err = types.SetItem(f.Value, base+i, arg, true, f.setOptions(&intBase)...)
+			f.keepBase(intBase, true, err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Function `nargsString()` describes the number of option-arguments
+// taken by a flag.
+func (f *Flag) nargsString() string {
+	if f.MinArgs == f.MaxArgs {
+		return fmt.Sprint(f.MaxArgs)
+	}
+	return fmt.Sprintf("%d to %d", f.MinArgs, f.MaxArgs)
+}
+
+// Function `argTags()` names the option-arguments of a flag taking
+// several with the words of its type tag (or the tag repeated),
+// bracketing those beyond the minimum, e.g. `X Y [Z]`.
+func (f *Flag) argTags() string {
+	words := strings.Fields(f.GetTypeTag())
+	if len(words) != f.MaxArgs {
+		tag := "ARG"
+		if len(words) > 0 {
+			tag = words[0]
+		}
+		words = make([]string, f.MaxArgs)
+		for i := range words {
+			words[i] = tag
+		}
+	}
+	for i := f.MinArgs; i < len(words); i++ {
+		words[i] = "[" + words[i] + "]"
+	}
+	return strings.Join(words, " ")
+}
+
 // Function `testOrSetOnly()` sets `f.Value` to `value` if `doSet` is
 // `true`, otherwise it silently tests, insofar as possible, whether
 // the set would succeed or not.
@@ -774,33 +1068,88 @@ func (f *Flag) testOrSetOnly(value interface{}, argPos int, doSet bool) error {
 	var ok bool
 	var str string
 	if str, ok = value.(string); !ok {
-		str = types.StrConv(value, types.WithSep(f.ListSeparator))
+		str = types.StrConv(value, f.convOptions()...)
 		if str == "" {
-			if doSet {
-				f.Failf("failed to convert '%v' to a nonempty string in '%s'", value, f)
-			}
-			return &FlagError{"cannot convert value to string"}
+			return f.failf(doSet, &FlagError{"cannot convert value to string"},
+				"failed to convert '%v' to a nonempty string in '%s'", value, f)
 		}
 	}
 
-	// Set the value from the string version
-	err := types.FromStr(f.Value, str, doSet, types.WithSep(f.ListSeparator))
+	if f.HasMembers() {
+		return f.testOrSetMembers(str, doSet)
+	}
+
+	// Set the value from the string version, if it passes any checks
+	err := f.validate(str)
+	if err == nil {
+		var base int
+		err = types.FromStr(f.Value, str, doSet, f.setOptions(&base)...)
+		f.keepBase(base, doSet, err)
+	}
 	if err != nil {
-		if doSet {
-			f.Failf("failed to convert '%s' to %T: %v", str, f.Value, err)
-		}
-		return err
+		err = &ValueError{Flag: f, Value: str, Err: err}
+		return f.failf(doSet, err, "%v", err)
 	}
 	return nil
 }
 
+// Function `convOptions()` returns the options for converting the
+// flag's value to and from strings.
+func (f *Flag) convOptions() []types.StrConvOption {
+	opts := []types.StrConvOption{
+		types.WithSep(f.ListSeparator),
+		types.WithLayouts(f.TimeLayouts...),
+		types.WithFamily(f.AddrFamily),
+		types.WithNetworks(f.Networks...),
+		types.WithDefaultPort(f.DefaultPort),
+		types.WithSchemes(f.Schemes...),
+		types.WithPairSep(f.PairSeparator),
+		types.WithDupKeys(f.DupKeys),
+		types.WithSeenKeys(f.seenKeys),
+		types.WithKeyCheck(f.checkKey),
+	}
+	if f.IntLiterals {
+		opts = append(opts, types.WithLiterals(f.OctalZero))
+		if f.intBase != 0 {
+			opts = append(opts, types.WithBase(f.intBase))
+		}
+	}
+	if f.IntUnits {
+		opts = append(opts, types.WithUnits())
+	}
+	return opts
+}
+
+// Function `setOptions()` returns the options for converting a string
+// to the value of the flag, with the base of an integer literal noted
+// in `base`, to be kept (see `keepBase()`) only once the value is set,
+// so that a value that is tested or rejected doesn't change how the
+// flag is rendered.
+func (f *Flag) setOptions(base *int) []types.StrConvOption {
+	*base = f.intBase
+	return append(f.convOptions(), types.WithSeenBase(base))
+}
+
+// Function `keepBase()` records the base noted by `setOptions()` if the
+// value was set.
+func (f *Flag) keepBase(base int, doSet bool, err error) {
+	if doSet && err == nil {
+		f.intBase = base
+	}
+}
+
 // Function `GetValue()` returns the current value of the flag as a
-// string.
+// string. Slices are joined with the flag's `ListSeparator` so that
+// the result can be given back to the flag as an optarg, as are the
+// members of a set (see `AsSet()`).
 func (f *Flag) GetValue() string {
 	if f.AliasFor != nil {
 		f = f.AliasFor
 	}
-	return types.StrConv(f.Value)
+	if f.HasMembers() {
+		return f.memberString()
+	}
+	return types.StrConv(f.Value, f.convOptions()...)
 }
 
 // Function `GetDefaultLen()` returns the length of the default slice
@@ -835,6 +1184,8 @@ func (f *Flag) GetDefault() interface{} {
 // f.Default slice or if f.Default is not a slice, otherwise it
 // returns `false`. It basically tells if the argument is an allowable
 // value for the flag.
+// Abbreviations, case variants, and aliases are resolved if enabled
+// (see `WithAbbreviations()`).
 func (f *Flag) InDefaults(ix interface{}) bool {
 	if f.AliasFor != nil {
 		f = f.AliasFor
@@ -845,8 +1196,20 @@ func (f *Flag) InDefaults(ix interface{}) bool {
 	if !types.IsSlice(f.Default) {
 		return true
 	}
-	for i := 0; i < types.SliceLen(f.Default); i++ {
-		d := types.ItemAt(f.Default, i)
+	if str, ok := ix.(string); ok {
+		var err error
+		if ix, err = f.resolveEnum(str); err != nil {
+			return false
+		}
+	}
+	return f.inEnum(f.Default, ix)
+}
+
+// Function `inEnum()` returns true if the argument, coerced to the
+// type of the items of the `enum` slice, is one of them.
+func (f *Flag) inEnum(enum interface{}, ix interface{}) bool {
+	for i := 0; i < types.SliceLen(enum); i++ {
+		d := types.ItemAt(enum, i)
 		v, err := types.CoerceScalar(d, ix)
 		if err != nil {
 			// TODO(emmet): think this through
@@ -854,13 +1217,22 @@ func (f *Flag) InDefaults(ix interface{}) bool {
 			return false
 		}
 		// fmt.Fprintf(os.Stderr, "%+v<%T> ?= %+v<%T> (%t) %+v<%T>\n", d, d, v, v, d == v, ix, ix)
-		if d == v {
+		if types.Equal(d, v) {
 			return true
 		}
 	}
 	return false
 }
 
+// Function `checkKey()` returns an error if the flag's keys are
+// constrained with `WithKeys()` and the key is not one of them.
+func (f *Flag) checkKey(key string) error {
+	if len(f.Keys) == 0 || f.inEnum(f.Keys, key) {
+		return nil
+	}
+	return fmt.Errorf("key '%s' is not one of %s", key, strings.Join(f.Keys, ", "))
+}
+
 func (f *Flag) GetDefaultDescription() string {
 	// TODO(emmet): handle aliases
 	buf := &bytes.Buffer{}
@@ -876,7 +1248,7 @@ func (f *Flag) GetDefaultDescription() string {
 			buf.WriteString("|" + types.StrConv(enum[1:len(enum)-1], types.WithSep("|")))
 		}
 	} else {
-		buf.WriteString(types.StrConv(f.Default) + "(default)")
+		buf.WriteString(types.StrConv(f.Default, f.convOptions()...) + "(default)")
 	}
 	return buf.String()
 }
@@ -893,9 +1265,15 @@ func (f *Flag) GetTypeTag() string {
 	if len(f.ValueTypeTag) > 0 {
 		return f.ValueTypeTag
 	}
+	if f.HasMembers() {
+		return "SET"
+	}
 	if f.GetDefaultLen() > 1 {
 		return "ENUM"
 	}
+	if types.IsMap(f.Value) {
+		return "KEY" + f.PairSeparator + "VALUE"
+	}
 	if types.IsInt(f.Value) {
 		return "INT"
 	}
@@ -908,6 +1286,27 @@ func (f *Flag) GetTypeTag() string {
 	if types.IsString(f.Value) {
 		return "STR"
 	}
+	if types.IsDuration(f.Value) {
+		return "DURATION"
+	}
+	if types.IsTime(f.Value) {
+		return "TIME"
+	}
+	if types.IsSize(f.Value) {
+		return "SIZE"
+	}
+	switch f.Value.(type) {
+	case *net.IP, *[]net.IP, *netip.Addr, *[]netip.Addr:
+		return "ADDR"
+	case *netip.Prefix, *[]netip.Prefix:
+		return "CIDR"
+	case *netip.AddrPort, *[]netip.AddrPort:
+		return "ADDR:PORT"
+	case *types.HostPort, *[]types.HostPort:
+		return "HOST:PORT"
+	case *url.URL, *[]url.URL:
+		return "URL"
+	}
 	return ""
 }
 
@@ -917,6 +1316,9 @@ func (f *Flag) GetTypeTag() string {
 // version, e.g. "-x, --example", otherwise just the version that's
 // defined, e.g. "-x" or "--example"
 func (f *Flag) String() string {
+	if f.operand {
+		return f.Long
+	}
 	if f.Short != NoShort && len(f.Long) > 1 {
 		return "-" + string(f.Short) + ", --" + f.Long
 	}
@@ -930,8 +1332,17 @@ func (f *Flag) String() string {
 }
 
 // Function `FormatShort()` returns a string showing a short flag with
-// its (optional?) type tag where appropriate.
+// its (optional?) type tag where appropriate, followed by its plus
+// form if it has one (see `PlusNegates()`).
 func (f *Flag) FormatShort() string {
+	short := f.formatShort()
+	if f.Short != NoShort && f.IsNegatable() {
+		return short + ", +" + string(f.Short)
+	}
+	return short
+}
+
+func (f *Flag) formatShort() string {
 	if f.Short == NoShort {
 		if f.Long == NoLong {
 			return "-NUM"
@@ -939,6 +1350,9 @@ func (f *Flag) FormatShort() string {
 		return ""
 	}
 
+	if f.HasNArgs() && !f.IsAlias() {
+		return "-" + string(f.Short) + " " + f.argTags()
+	}
 	tag := f.GetTypeTag()
 	if len(tag) == 0 || f.IsAlias() {
 		return "-" + string(f.Short)
@@ -951,12 +1365,30 @@ func (f *Flag) FormatShort() string {
 }
 
 // Function `FormatLong()` returns a string showing a long flag with
-// its (optional?) type tag where appropriate.
+// its (optional?) type tag where appropriate, followed by its plus
+// form if it has one and there's no short flag.
 func (f *Flag) FormatLong() string {
+	long := f.formatLong()
+	if f.Short == NoShort && f.Long != NoLong && f.IsNegatable() {
+		return long + ", +" + f.Long
+	}
+	return long
+}
+
+func (f *Flag) formatLong() string {
 	if f.Long == NoLong {
 		return ""
 	}
+	if f.operand {
+		if tag := f.GetTypeTag(); tag != "" && !f.IsAlias() {
+			return f.Long + "=" + tag
+		}
+		return f.Long
+	}
 
+	if f.HasNArgs() && !f.IsAlias() {
+		return "--" + f.Long + " " + f.argTags()
+	}
 	tag := f.GetTypeTag()
 	if len(tag) == 0 || f.IsAlias() {
 		return "--" + f.Long
@@ -1001,6 +1433,13 @@ func (f *Flag) DescString() string {
 	if f.Type.TstNotImplementedBit() {
 		return "not implemented"
 	}
+	if f.HasMembers() {
+		return fmt.Sprintf("%s (any of %s, %s, %s)", f.Usage,
+			strings.Join(f.Members, ", "), SetAll, SetNone)
+	}
+	if len(f.Ranges) > 0 {
+		return fmt.Sprintf("%s (%s)", f.Usage, f.rangesString())
+	}
 	// TODO(emmet): handle non-aliases
 	return f.Usage
 }
@@ -1061,7 +1500,7 @@ func WithListSeparator(sep rune) FlagOption {
 		if f.IsHyphenNum() {
 			log.Panicf("hyphen-num idiom cannot have a list sepearator")
 		}
-		if !types.IsSlice(f.Value) {
+		if !types.IsSlice(f.Value) && !types.IsMap(f.Value) {
 			log.Panicf("cannot set separator for non-list value %s", f)
 		}
 		f.ListSeparator = string(sep)
@@ -1069,6 +1508,94 @@ func WithListSeparator(sep rune) FlagOption {
 	}
 }
 
+// Option `WithNArgs()` makes a flag take `n` option-arguments, as in
+// `--point X Y`, rather than one. The first may be attached (e.g.
+// `--point=X Y` or `-pX Y`) and the rest follow as separate
+// arguments, none of which may look like an option. The value must
+// be a slice, to which the arguments of each appearance are appended,
+// or an array of at least `n` elements, in which they are stored from
+// the start. A type tag having a word for each argument (e.g.
+// `WithTypeTag("X Y")`) names them in help/usage output.
+func WithNArgs(n int) FlagOption {
+	return WithNArgsRange(n, n)
+}
+
+// Option `WithNArgsRange()` makes a flag take between `min` and `max`
+// option-arguments (see `WithNArgs()`). Arguments beyond the minimum
+// are taken greedily, up to the maximum, until one looks like an
+// option or is `--`.
+func WithNArgsRange(min, max int) FlagOption {
+	return func(f *Flag) error {
+		if min < 1 || max < min {
+			log.Panicf("invalid number of arguments (%d to %d) for '%s'", min, max, f)
+		}
+		if f.IsHyphenNum() || f.IsCounter() || f.IsBool() || f.Type.TstDefOptionalBit() {
+			log.Panicf("'%s' cannot take several arguments", f)
+		}
+		if !types.IsSlice(f.Value) && !types.IsArray(f.Value) {
+			log.Panicf("value of '%s' taking several arguments must be a slice or array", f)
+		}
+		if n := types.SliceLen(f.Value); types.IsArray(f.Value) && n < max {
+			log.Panicf("array of %d elements cannot hold %d arguments for '%s'", n, max, f)
+		}
+		f.MinArgs = min
+		f.MaxArgs = max
+		return nil
+	}
+}
+
+// Option `WithPairSeparator()` sets the separator between the key and
+// the value of the pairs given to a map-valued flag, which is `=` by
+// default (see `DefaultPairSeparator`). Several pairs can be given in
+// one optarg, separated by the list separator (see
+// `WithListSeparator()`), e.g. `--set a=1,b=2`. A list separator
+// followed by something with no pair separator is part of the
+// previous value, so `--set a=1,2` gives `a` the value `1,2`.
+func WithPairSeparator(sep rune) FlagOption {
+	return func(f *Flag) error {
+		if !types.IsMap(f.Value) {
+			log.Panicf("cannot set pair separator for non-map value %s", f)
+		}
+		if string(sep) == f.ListSeparator {
+			log.Panicf("pair separator is the same as the list separator for %s", f)
+		}
+		f.PairSeparator = string(sep)
+		return nil
+	}
+}
+
+// Option `WithDupKeys()` sets what happens when a key is given more
+// than once to a map-valued flag: by default, the last value prevails
+// (`types.DupKeysOverwrite`), but the first can be kept instead
+// (`types.DupKeysKeepFirst`) or the repeat treated as an error
+// (`types.DupKeysError`). Keys in the map before parsing aren't
+// repeats, so they can always be overridden once.
+func WithDupKeys(policy types.DupKeyPolicy) FlagOption {
+	return func(f *Flag) error {
+		if !types.IsMap(f.Value) {
+			log.Panicf("WithDupKeys() given for non-map value %s", f)
+		}
+		f.DupKeys = policy
+		return nil
+	}
+}
+
+// Option `WithKeys()` constrains the keys that can be given to a
+// map-valued flag, in the same way as a default slice constrains the
+// values of other flags.
+func WithKeys(keys ...string) FlagOption {
+	return func(f *Flag) error {
+		if !types.IsMap(f.Value) {
+			log.Panicf("WithKeys() given for non-map value %s", f)
+		}
+		if len(keys) == 0 {
+			log.Panicf("no keys given for %s", f)
+		}
+		f.Keys = keys
+		return nil
+	}
+}
+
 // Option `WithAlias()` allows an alias to be set when a flag is
 // created via `Var()` and requires it to be marked as obsolete or
 // not.
@@ -1242,6 +1769,155 @@ func NotImplemented() FlagOption {
 	}
 }
 
+// Option `WithPlusNum()` makes the flag the target of the `+NUM` idiom,
+// as in `tail +5` (start at line 5), where an argument consisting of a
+// plus sign and a number sets the flag to that number. The flag must
+// be a scalar integer and the idiom can only be used once in a
+// `FlagSet`. It is distinct from the `-NUM` idiom, which can target a
+// different flag.
+func WithPlusNum() FlagOption {
+	return func(f *Flag) error {
+		if f.IsHyphenNum() || f.IsAlias() || !f.IsScalar() || !(types.IsInt(f.Value) || types.IsUint(f.Value)) {
+			log.Panicf("a scalar integer flag is required for the +NUM idiom, not '%s'", f)
+		}
+		f.Type.SetPlusNumBit()
+		return nil
+	}
+}
+
+// Option `WithNegativeArgs()` lets a numeric flag take a negative
+// number given as a separate argument, as in `head -c -20`, even
+// though it looks like a flag. Otherwise, such an argument is parsed
+// as a flag (or the -NUM idiom) and the flag gets no option-argument.
+func WithNegativeArgs() FlagOption {
+	return func(f *Flag) error {
+		if f.IsBool() || f.IsCounter() || f.HasCallback() ||
+			!(f.IsNumber() || types.IsDuration(f.Value) || types.IsSize(f.Value)) {
+			log.Panicf("cannot take negative arguments for non-numeric value of '%s'", f)
+		}
+		f.NegativeArgs = true
+		return nil
+	}
+}
+
+// Option `WithIntLiterals()` lets the integers given to a flag be Go/C
+// literals, with a `0x`, `0o`, or `0b` prefix for hexadecimal, octal,
+// or binary, and underscores between digits (e.g. `--mask=0x1ff`).
+// If `octalZero` is true, a leading zero also means octal, as for
+// `chmod` and `umask` (e.g. `--mode=0755`). The value is rendered by
+// `GetValue()`, and so in help and `Args()`, in the base that it was
+// last given in.
+func WithIntLiterals(octalZero bool) FlagOption {
+	return func(f *Flag) error {
+		if !types.IsInt(f.Value) && !types.IsUint(f.Value) {
+			log.Panicf("cannot give integer literals to non-integer value of '%s'", f)
+		}
+		f.IntLiterals = true
+		f.OctalZero = octalZero
+		return nil
+	}
+}
+
+// Option `WithIntUnits()` lets the integers given to a flag have a
+// multiplicative suffix, as for a `types.ByteSize` (e.g. `--count=10K`
+// for 10240 or `--count=10KB` for 10000).
+func WithIntUnits() FlagOption {
+	return func(f *Flag) error {
+		if !types.IsInt(f.Value) && !types.IsUint(f.Value) {
+			log.Panicf("cannot give units to non-integer value of '%s'", f)
+		}
+		f.IntUnits = true
+		return nil
+	}
+}
+
+// Option `WithTimeLayouts()` sets the layouts (see `time.Layout`)
+// with which an option-argument is parsed for a `time.Time` flag,
+// trying each in turn. The first is also used to format the value. By
+// default, `types.TimeLayouts` is used, which accepts RFC 3339 times
+// and dates like `2024-01-02`.
+func WithTimeLayouts(layouts ...string) FlagOption {
+	return func(f *Flag) error {
+		if len(layouts) == 0 {
+			log.Panicf("no time layouts given for '%s'", f)
+		}
+		f.TimeLayouts = layouts
+		return nil
+	}
+}
+
+// Option `WithAddrFamily()` restricts the IP addresses accepted by a
+// network address flag to `types.IPv4Only` or `types.IPv6Only`.
+// IPv4-mapped IPv6 addresses count as IPv4. The host of a
+// `types.HostPort` is only checked if it is an IP address.
+func WithAddrFamily(family types.AddrFamily) FlagOption {
+	return func(f *Flag) error {
+		if !f.hasAddr() {
+			log.Panicf("WithAddrFamily() given for non-address flag '%s'", f)
+		}
+		f.AddrFamily = family
+		return nil
+	}
+}
+
+// Option `WithNetworks()` restricts the IP addresses accepted by a
+// network address flag to those within one of the given networks in
+// CIDR notation (e.g. `10.0.0.0/8`). A prefix is accepted if it is
+// contained in one of the networks.
+func WithNetworks(cidrs ...string) FlagOption {
+	return func(f *Flag) error {
+		if !f.hasAddr() {
+			log.Panicf("WithNetworks() given for non-address flag '%s'", f)
+		}
+		for _, cidr := range cidrs {
+			network, err := netip.ParsePrefix(cidr)
+			if err != nil {
+				log.Panicf("invalid network for '%s': %v", f, err)
+			}
+			f.Networks = append(f.Networks, network.Masked())
+		}
+		return nil
+	}
+}
+
+// Function `hasAddr()` returns true if the flag's value holds IP
+// addresses, or a host that might be one.
+func (f *Flag) hasAddr() bool {
+	switch f.Value.(type) {
+	case *url.URL, *[]url.URL:
+		return false
+	}
+	return types.IsNet(f.Value)
+}
+
+// Option `WithDefaultPort()` sets the port of a `types.HostPort` flag
+// given only a host. Without it, the port is required.
+func WithDefaultPort(port uint16) FlagOption {
+	return func(f *Flag) error {
+		switch f.Value.(type) {
+		case *types.HostPort, *[]types.HostPort:
+		default:
+			log.Panicf("WithDefaultPort() given for non-host:port flag '%s'", f)
+		}
+		f.DefaultPort = port
+		return nil
+	}
+}
+
+// Option `WithSchemes()` restricts the URLs accepted by a `url.URL`
+// flag to those having one of the given schemes.
+func WithSchemes(schemes ...string) FlagOption {
+	return func(f *Flag) error {
+		switch f.Value.(type) {
+		case *url.URL, *[]url.URL:
+		default:
+			log.Panicf("WithSchemes() given for non-URL flag '%s'", f)
+		}
+		f.Schemes = schemes
+		return nil
+	}
+}
+
 // Option `WithTypeTag()` supplies a type tag used to label optargs in
 // help/usage output. See how `GLOB`, `FILE`, and `PATTERN` are used
 // in `grep`'s output for example.
@@ -1329,8 +2005,8 @@ func NewFlag(value interface{}, short rune, long string, usage string, opts ...F
 	}
 	if short == NoShort && long == NoLong {
 		// Special -NUM idiom
-		if valType.TstSliceBit() || !valType.TstUintBit() {
-			log.Panicf("a scalar unsigned integer is required for the -NUM idiom")
+		if valType.TstSliceBit() || !(valType.TstUintBit() || valType.TstIntBit()) {
+			log.Panicf("a scalar integer is required for the -NUM idiom")
 		}
 	}
 	f := &Flag{
@@ -1340,20 +2016,97 @@ func NewFlag(value interface{}, short rune, long string, usage string, opts ...F
 		Usage:         usage,
 		Count:         0,
 		ListSeparator: DefaultListSeparator,
+		PairSeparator: DefaultPairSeparator,
 		Mutexes:       map[string]struct{}{},
 	}
-	if valType.TstSliceBit() {
+	if valType.TstSliceBit() || valType.TstMapBit() {
 		f.Type.SetRepeatsBit()
 	}
+	if valType.TstMapBit() {
+		f.seenKeys = map[string]struct{}{}
+	}
 	for i, opt := range opts {
 		err := opt(f)
 		if err != nil {
 			log.Panicf("error setting option %d for flag '%s'", i, f)
 		}
 	}
+	f.Snapshot()
 	return f
 }
 
+// Function `Snapshot()` records the current value of a flag as the
+// value that `Reset()` restores. It is called by `NewFlag()`, after
+// any default has been applied, so it is only necessary to call it
+// again if the bound variable is changed outside of argument
+// processing (e.g. from a configuration file) and that value, rather
+// than the original one, should survive a `Reset()`.
+func (f *Flag) Snapshot() {
+	f.hasInitial = false
+	if f.IsAlias() {
+		// Aliases have no value of their own
+		return
+	}
+	rv, ok := f.target()
+	if !ok || rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return
+	}
+	// A copy, rather than a string, so that any value can be
+	// restored, whatever separators it contains
+	f.initial = copyValue(rv.Elem())
+	f.initialBase = f.intBase
+	f.hasInitial = true
+	f.clearSeenKeys()
+}
+
+// A `wrapper` is a `SetValue` wrapping a variable of our own making
+// (e.g. a `typedValue`), which can be read back, unlike the value
+// behind anything else implementing `SetValue`.
+type wrapper interface {
+	target() interface{}
+}
+
+// Function `target()` returns the variable holding the value of a
+// flag, or `false` if there is no way of reading it back.
+func (f *Flag) target() (reflect.Value, bool) {
+	if w, ok := f.Value.(wrapper); ok {
+		return reflect.ValueOf(w.target()), true
+	}
+	if types.IsSetter(f.Value) {
+		return reflect.Value{}, false
+	}
+	return reflect.ValueOf(f.Value), true
+}
+
+// Function `clearSeenKeys()` forgets the keys given to a map-valued
+// flag, so that none is a repeat.
+func (f *Flag) clearSeenKeys() {
+	for key := range f.seenKeys {
+		delete(f.seenKeys, key)
+	}
+}
+
+// Function `Reset()` restores the value of a flag to what it was when
+// the flag was created (or last snapshotted with `Snapshot()`),
+// truncating slices and emptying maps before refilling them, so that
+// they keep their identity, and clears its count. Values implementing
+// `SetValue` are left alone, unless they're those of a flag added with
+// `Add()`.
+func (f *Flag) Reset() {
+	if f.IsAlias() {
+		return
+	}
+	f.Count = 0
+	if !f.hasInitial {
+		return
+	}
+	// Neither the restored keys nor those given before are repeats
+	f.clearSeenKeys()
+	rv, _ := f.target()
+	restoreValue(rv.Elem(), f.initial)
+	f.intBase = f.initialBase
+}
+
 // Function `NewAlias()` creates a new alias (short and/or long) for a
 // `Flag`.
 func (f *Flag) NewAlias(short rune, long string, opts ...FlagOption) *Flag {
@@ -1421,10 +2174,11 @@ func (f *Flag) IgnoreRepeats() bool {
 	return f.Type.TstIgnoreRepeatsBit()
 }
 func (f *Flag) IsScalar() bool {
-	return !types.IsSlice(f.Value)
+	return !types.IsSlice(f.Value) && !types.IsMap(f.Value) && !types.IsArray(f.Value)
 }
 func (f *Flag) IsBool() bool {
-	return types.IsBool(f.Value)
+	// A set of booleans (see `AsSet()`) takes members
+	return (types.IsBool(f.Value) && !f.HasMembers()) || types.IsBoolSetter(f.Value)
 }
 func (f *Flag) IsNumber() bool {
 	return types.IsNum(f.Value)
@@ -1435,11 +2189,56 @@ func (f *Flag) IsHyphenNum() bool {
 func (f *Flag) HasCallback() bool {
 	return f.Callback != nil
 }
+func (f *Flag) HasNArgs() bool {
+	return f.MaxArgs > 0
+}
+
+// Function `requiresArg()` reports whether a flag requires an
+// option-argument. An equivalent (see `Equ()`) has its own and
+// requires none.
+func (f *Flag) requiresArg() bool {
+	if f.AliasFor != nil {
+		if f.Value != nil {
+			return false
+		}
+		f = f.AliasFor
+	}
+	return !f.IsBool() && !f.IsCounter() && !f.Type.TstDefOptionalBit()
+}
+
+func (f *Flag) IsPlusNum() bool {
+	return f.Type.TstPlusNumBit()
+}
+
+// Function `takesNegativeArgs()` reports whether a flag takes an
+// option-argument that looks like a flag as a negative number (see
+// `WithNegativeArgs()`).
+func (f *Flag) takesNegativeArgs() bool {
+	if f.AliasFor != nil {
+		f = f.AliasFor
+	}
+	return f.NegativeArgs && !f.Type.TstDefOptionalBit()
+}
 
 func (f *Flag) Failf(format string, args ...interface{}) {
 	f.ParentFlagSet().Failf(format, args...)
 }
 
+func (f *Flag) FileFailf(format string, args ...interface{}) {
+	f.ParentFlagSet().FileFailf(format, args...)
+}
+
+// Function `failf()` reports a failure to set a flag, if `doSet` says
+// it is being set rather than tested, and returns `err`, marked as
+// reported if it was, so that the parser doesn't report it again.
+func (f *Flag) failf(doSet bool, err error, format string, args ...interface{}) error {
+	if !doSet {
+		return err
+	}
+	f.Failf(format, args...)
+	return &reportedError{err}
+}
+
 func (f *Flag) Infof(format string, args ...interface{}) {
 	f.ParentFlagSet().Infof(format, args...)
 }
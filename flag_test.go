@@ -2,8 +2,15 @@ package fflag
 
 import (
 	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
 	"reflect"
+	"regexp"
 	"testing"
+	"time"
+
+	"github.com/EmmetCaulfield/fflag/pkg/types"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -321,3 +328,418 @@ func TestLongGet(t *testing.T) {
 		t.Error("error looking up string(\"c\")")
 	}
 }
+
+func TestTimeAndSizeFlags(u *testing.T) {
+	t := assert.TestingT(u)
+	var timeout time.Duration
+	var when time.Time
+	var size types.ByteSize
+	var delays []time.Duration
+	var sizes []types.ByteSize
+	fs := NewFlagSet(WithSilentFail(), WithContinueOnFail())
+	fs.Var(&timeout, 't', "timeout", "wait this long",
+		WithDefault([]time.Duration{time.Second, time.Minute}), WithRepeats(false))
+	fs.Var(&when, NoShort, "when", "a date", WithTimeLayouts("02/01/2006", "2006-01-02"),
+		WithRepeats(false))
+	fs.Var(&size, 's', "size", "a size", WithRepeats(false))
+	fs.Var(&delays, 'd', NoLong, "delays")
+	fs.Var(&sizes, NoShort, "sizes", "sizes")
+
+	assert.Equal(t, "-t ENUM, --timeout=ENUM", fs.Lookup("timeout").FlagString())
+	assert.Equal(t, "    --when=TIME", fs.Lookup("when").FlagString())
+	assert.Equal(t, "-s SIZE, --size=SIZE", fs.Lookup("size").FlagString())
+	assert.Equal(t, time.Second, timeout)
+
+	fs.Parse([]string{"-t", "1m0s", "--when", "29/02/2024", "-s10K", "-d", "1s,2ms",
+		"--sizes=1M,2KB"})
+	assert.Equal(t, time.Minute, timeout)
+	assert.Equal(t, 29, when.Day())
+	assert.Equal(t, "29/02/2024", fs.Lookup("when").GetValue())
+	assert.Equal(t, types.ByteSize(10240), size)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Millisecond}, delays)
+	assert.Equal(t, []types.ByteSize{1 << 20, 2000}, sizes)
+
+	// Not among the defaults
+	fs.Parse([]string{"-t", "2m"})
+	assert.Equal(t, time.Minute, timeout)
+	// ...but this is, in another spelling
+	fs.Parse([]string{"--timeout=60s"})
+	assert.Equal(t, time.Minute, timeout)
+
+	fs.Parse([]string{"--when=2024-03-01"})
+	assert.Equal(t, time.March, when.Month())
+	assert.Equal(t, "01/03/2024", fs.Lookup("when").GetValue())
+	fs.Parse([]string{"--when=tomorrow", "-s", "1X"})
+	assert.Equal(t, time.March, when.Month())
+	assert.Equal(t, types.ByteSize(10240), size)
+}
+
+func TestNetworkFlags(u *testing.T) {
+	t := assert.TestingT(u)
+	var listen types.HostPort
+	var peers []netip.AddrPort
+	var allow []netip.Prefix
+	var proxy url.URL
+	var ip net.IP
+	fs := NewFlagSet(WithSilentFail(), WithContinueOnFail())
+	fs.Var(&listen, 'l', "listen", "listen on", WithDefaultPort(8080), WithRepeats(false))
+	fs.Var(&peers, 'p', "peer", "a peer", WithAddrFamily(types.IPv4Only))
+	fs.Var(&allow, NoShort, "allow", "allowed networks", WithNetworks("10.0.0.0/8", "fd00::/8"))
+	fs.Var(&proxy, NoShort, "proxy", "use a proxy", WithSchemes("http", "socks5"),
+		WithRepeats(false))
+	fs.Var(&ip, NoShort, "ip", "an address")
+
+	assert.Equal(t, "-l HOST:PORT, --listen=HOST:PORT", fs.Lookup("listen").FlagString())
+	assert.Equal(t, "-p ADDR:PORT, --peer=ADDR:PORT", fs.Lookup("peer").FlagString())
+	assert.Equal(t, "    --allow=CIDR", fs.Lookup("allow").FlagString())
+	assert.Equal(t, "    --proxy=URL", fs.Lookup("proxy").FlagString())
+	assert.Equal(t, "    --ip=ADDR", fs.Lookup("ip").FlagString())
+	assert.Panics(t, func() { fs.Var(&ip, NoShort, "ip6", "", WithSchemes("http")) })
+	assert.Panics(t, func() { fs.Var(&proxy, NoShort, "url", "", WithAddrFamily(types.IPv4Only)) })
+	assert.Panics(t, func() { fs.Var(&listen, NoShort, "hp", "", WithNetworks("10/8")) })
+
+	fs.Parse([]string{"--listen", "[::1]", "-p", "10.0.0.1:53,10.0.0.2:53",
+		"--allow=10.1.0.0/16", "--allow", "fd12::/16", "--proxy", "socks5://gw:1080",
+		"--ip=::FFFF:10.0.0.1"})
+	assert.Equal(t, types.HostPort{Host: "::1", Port: 8080}, listen)
+	assert.Equal(t, "[::1]:8080", fs.Lookup("listen").GetValue())
+	assert.Equal(t, "10.0.0.1:53,10.0.0.2:53", fs.Lookup("peer").GetValue())
+	assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16"),
+		netip.MustParsePrefix("fd12::/16")}, allow)
+	assert.Equal(t, "gw:1080", proxy.Host)
+	assert.Equal(t, "10.0.0.1", fs.Lookup("ip").GetValue())
+
+	err := fs.Lookup("peer").Set("[::1]:53", 1)
+	var verr *ValueError
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Equal(t, "peer", verr.Flag.Long)
+		assert.Equal(t, "[::1]:53", verr.Value)
+		assert.Contains(t, err.Error(), "'-p, --peer'")
+		assert.Contains(t, err.Error(), "not IPv4")
+	}
+	assert.Error(t, fs.Lookup("allow").Set("192.168.0.0/16", 1))
+	assert.Error(t, fs.Lookup("proxy").Set("ftp://gw", 1))
+	assert.Error(t, fs.Lookup("ip").Set("10.0.0.256", 1))
+	assert.Len(t, peers, 2)
+	assert.Len(t, allow, 2)
+	assert.Equal(t, "socks5", proxy.Scheme)
+}
+
+func TestMapFlags(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixEquals = true
+	PosixDoubleHyphen = true
+	PosixOperandStop = true
+	labels := map[string]string{"env": "dev"}
+	var set map[string]int
+	var limits map[string]types.ByteSize
+	fs := NewFlagSet(WithSilentFail(), WithContinueOnFail())
+	fs.Var(&labels, 'L', "label", "add a label", WithKeys("env", "team"),
+		WithDupKeys(types.DupKeysError))
+	fs.Var(&set, NoShort, "set", "set values", WithPairSeparator(':'), WithListSeparator(';'))
+	fs.Var(&limits, 'm', NoLong, "memory limits", WithDupKeys(types.DupKeysKeepFirst))
+	assert.Panics(t, func() { fs.Var(&set, NoShort, "set2", "", WithRepeats(false)) })
+	assert.Panics(t, func() { fs.Var(new(int), NoShort, "int", "", WithKeys("a")) })
+	assert.Panics(t, func() { fs.Var(&set, NoShort, "set3", "", WithPairSeparator(',')) })
+
+	assert.Equal(t, "-L KEY=VALUE, --label=KEY=VALUE", fs.Lookup("label").FlagString())
+	assert.Equal(t, "    --set=KEY:VALUE", fs.Lookup("set").FlagString())
+	assert.True(t, fs.Lookup("set").IsRepeatable())
+
+	fs.Parse([]string{"-L", "env=prod", "--label=team=infra", "--set", "a:1;b:2",
+		"--set=c:3", "-m", "web=512M,db=2G", "-mweb=1G"})
+	assert.Equal(t, map[string]string{"env": "prod", "team": "infra"}, labels)
+	assert.Equal(t, map[string]int{"a": 1, "b": 2, "c": 3}, set)
+	assert.Equal(t, map[string]types.ByteSize{"web": 512 << 20, "db": 2 << 30}, limits)
+	assert.Equal(t, "a:1;b:2;c:3", fs.Lookup("set").GetValue())
+	assert.Equal(t, []string{"--label=env=prod,team=infra", "--set=a:1;b:2;c:3",
+		"-m", "db=2G,web=512M"}, fs.Args(LongArgStyle))
+
+	err := fs.Lookup("label").Set("env=test", 1)
+	var verr *ValueError
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Contains(t, err.Error(), "duplicate key 'env'")
+	}
+	err = fs.Lookup("label").Set("owner=me", 1)
+	assert.ErrorContains(t, err, "key 'owner' is not one of env, team")
+	assert.Error(t, fs.Lookup("set").Set("a=1", 1))
+	assert.Equal(t, "prod", labels["env"])
+
+	fs.Reset()
+	assert.Equal(t, map[string]string{"env": "dev"}, labels)
+	assert.Empty(t, set)
+	assert.NotNil(t, set)
+	fs.Parse([]string{"-L", "env=prod"})
+	assert.Equal(t, "prod", labels["env"])
+
+	// Values containing the list separator survive a round trip
+	fs.Parse([]string{"-m", "web=1G", "--set", "a:1", "-L", "team=x;y,z"})
+	args := fs.Args(ShortArgStyle)
+	assert.Contains(t, args, "env=prod,team=x;y,z")
+	fs.Reset()
+	fs.Parse(args)
+	assert.Equal(t, map[string]string{"env": "prod", "team": "x;y,z"}, labels)
+	fs.Reset()
+	fs.Parse([]string{"-L", "env=prod"})
+
+	c := fs.Clone()
+	assert.Error(t, c.Lookup("label").Set("env=qa", 1))
+	assert.Equal(t, map[string]string{"env": "prod"}, c.Get("label"))
+	c.Reset()
+	assert.NoError(t, c.Lookup("label").Set("env=qa", 1))
+	assert.Equal(t, "prod", labels["env"])
+}
+
+func TestSetFlags(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixEquals = true
+	PosixDoubleHyphen = true
+	PosixOperandStop = true
+	var debug uint8
+	var trace []string
+	var feat map[string]bool
+	var dirs []string
+	fs := NewFlagSet(WithSilentFail(), WithContinueOnFail())
+	fs.Var(&debug, 'D', "debug", "debug subsystems", AsSet("parse", "io", "net"),
+		WithDefault("parse"))
+	fs.Var(&trace, NoShort, "trace", "trace subsystems", AsSet("parse", "io", "net"))
+	fs.Var(&feat, 'F', NoLong, "enable features", AsSet("fast", "safe"))
+	fs.Var(&dirs, 'd', NoLong, "directories", WithDefault([]string{"read", "skip", "recurse"}))
+	assert.Panics(t, func() { fs.Var(new(string), NoShort, "s", "", AsSet("a")) })
+	assert.Panics(t, func() { fs.Var(new(int), NoShort, "dup", "", AsSet("a", "a")) })
+	assert.Panics(t, func() { fs.Var(new(int), NoShort, "kw", "", AsSet("a", "all")) })
+	assert.Panics(t, func() { fs.Var(new(int8), NoShort, "big", "", AsSet("a", "b", "c", "d", "e", "f", "g", "h", "i")) })
+	assert.Panics(t, func() { fs.Var(new(int), NoShort, "late", "", WithDefault(1), AsSet("a")) })
+
+	assert.Equal(t, uint8(1), debug)
+	assert.Equal(t, "-D SET, --debug=SET", fs.Lookup("debug").FlagString())
+	assert.Equal(t, "debug subsystems (any of parse, io, net, all, none)",
+		fs.Lookup("debug").DescString())
+
+	fs.Parse([]string{"--debug=+net", "-D-parse,+io", "--trace=net,parse",
+		"--trace", "+io", "-F", "all,-safe", "-d", "read,skip"})
+	assert.Equal(t, uint8(6), debug)
+	assert.Equal(t, "io,net", fs.Lookup("debug").GetValue())
+	assert.Equal(t, []string{"parse", "io", "net"}, trace)
+	assert.Equal(t, map[string]bool{"fast": true}, feat)
+	assert.Equal(t, []string{"read", "read", "skip"}, dirs)
+	assert.Equal(t, []string{"-D", "io,net", "--trace=parse,io,net", "-F", "fast",
+		"-d", "read,read,skip"}, fs.Args(ShortArgStyle))
+
+	assert.NoError(t, fs.Lookup("debug").Set("io", 1))
+	assert.Equal(t, uint8(2), debug)
+	assert.NoError(t, fs.Lookup("debug").Set("all,-io", 1))
+	assert.Equal(t, uint8(5), debug)
+	assert.NoError(t, fs.Lookup("trace").Set("none", 1))
+	assert.Empty(t, trace)
+	err := fs.Lookup("debug").Set("+disk", 1)
+	var verr *ValueError
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Contains(t, err.Error(), "'disk' is not one of parse, io, net")
+	}
+	assert.Equal(t, uint8(5), debug)
+	assert.Error(t, fs.Lookup("d").Set("read,foo", 1))
+
+	fs.Reset()
+	assert.Equal(t, uint8(1), debug)
+	assert.Empty(t, trace)
+	assert.Empty(t, feat)
+
+	// A set of booleans takes members
+	assert.False(t, fs.Lookup('F').IsBool())
+	fs.Parse([]string{"-F", "safe", "fast"})
+	assert.Equal(t, map[string]bool{"safe": true}, feat)
+	assert.Equal(t, []string{"fast"}, []string(*fs.OutputArgs))
+	assert.Equal(t, []string{"-F", "safe", "--", "fast"}, fs.Args(ShortArgStyle))
+}
+
+func TestEnumAbbreviations(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixEquals = true
+	PosixDoubleHyphen = true
+	PosixOperandStop = true
+	color := "never"
+	dirs := "read"
+	var modes []string
+	var pair []string
+	fs := NewFlagSet(WithSilentFail(), WithContinueOnFail())
+	fs.Var(&color, NoShort, "color", "colorize output",
+		WithOptionalDefault([]string{"auto", "never", "always", "all"}),
+		WithAbbreviations(), WithFoldCase(), WithRepeats(false),
+		WithValueAliases(map[string]string{"yes": "always", "force": "always", "no": "never"}))
+	fs.Var(&dirs, 'd', "directories", "how to handle directories",
+		WithDefault([]string{"read", "skip", "recurse"}), WithAbbreviations(), WithRepeats(false))
+	fs.Var(&modes, 'm', NoLong, "modes", WithDefault([]string{"fast", "safe"}),
+		WithAbbreviations())
+	fs.Var(&pair, 'p', NoLong, "pair", WithDefault([]string{"left", "right"}),
+		WithAbbreviations(), WithNArgs(2))
+	assert.Panics(t, func() { fs.Var(new(string), NoShort, "early", "", WithAbbreviations()) })
+	assert.Panics(t, func() {
+		fs.Var(new(string), NoShort, "bad", "", WithDefault([]string{"a", "b"}),
+			WithValueAliases(map[string]string{"c": "d"}))
+	})
+
+	fs.Parse([]string{"--color=ALW", "-drec", "-m", "f,s", "-p", "l", "ri"})
+	assert.Equal(t, "always", color)
+	assert.Equal(t, "recurse", dirs)
+	assert.Equal(t, []string{"fast", "fast", "safe"}, modes)
+	assert.Equal(t, []string{"left", "left", "right"}, pair)
+
+	for _, c := range [][2]string{{"au", "auto"}, {"Never", "never"}, {"yes", "always"},
+		{"fo", "always"}, {"n", "never"}, {"all", "all"}} {
+		assert.NoError(t, fs.Lookup("color").Set(c[0], 1), c[0])
+		assert.Equal(t, c[1], color, c[0])
+	}
+	assert.True(t, fs.Lookup("color").InDefaults("NEV"))
+	assert.False(t, fs.Lookup("color").InDefaults("al"))
+
+	err := fs.Lookup("color").Set("al", 1)
+	var verr *ValueError
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Contains(t, err.Error(), "ambiguous, could be all, always")
+	}
+	assert.ErrorContains(t, fs.Lookup("color").Set("blue", 1), "value constrained by defaults")
+	assert.Error(t, fs.Lookup("d").Set("REC", 1))
+	assert.Error(t, fs.Lookup("m").Set("fast,x", 1))
+	assert.Equal(t, "all", color)
+}
+
+func TestValidators(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixEquals = true
+	PosixDoubleHyphen = true
+	PosixOperandStop = true
+	var port uint16
+	var sizes []int
+	var limits map[string]time.Duration
+	var name string
+	var point []float64
+	var percents []int
+	var timeout time.Duration
+	powerOfTwo := func(v interface{}) error {
+		if n := v.(int); n&(n-1) != 0 {
+			return fmt.Errorf("%d is not a power of two", n)
+		}
+		return nil
+	}
+	fs := NewFlagSet(WithSilentFail(), WithContinueOnFail())
+	fs.Var(&port, 'p', "port", "listen on PORT", WithRange(1024, 65535), WithRepeats(false))
+	fs.Var(&sizes, 's', NoLong, "block sizes", WithRange(1, 4096), WithValidator(powerOfTwo))
+	fs.Var(&limits, 'l', NoLong, "time limits", WithRange(time.Second, time.Minute))
+	fs.Var(&name, 'n', "name", "a name", WithPattern(regexp.MustCompile(`^[a-z]+$`)),
+		WithRepeats(false))
+	fs.Var(&point, NoShort, "point", "a point", WithNArgs(2), WithRange(-1, 1.0))
+	fs.Var(&percents, NoShort, "percent", "percentages", WithRangeStep(0, 100, 5))
+	fs.Var(&timeout, NoShort, "timeout", "a timeout",
+		WithRangeStep(time.Second, time.Minute, 15*time.Second), WithRepeats(false))
+	assert.Panics(t, func() { fs.Var(new(string), NoShort, "str", "", WithRange(1, 2)) })
+	assert.Panics(t, func() { fs.Var(new(int), NoShort, "rev", "", WithRange(2, 1)) })
+	assert.Panics(t, func() { fs.Var(new(int), NoShort, "flat", "", WithRangeStep(1, 2, 0)) })
+	assert.Panics(t, func() {
+		fs.Var(new(string), NoShort, "cb", "", WithCallback(func(*Flag, string, int) error { return nil }),
+			WithValidator(powerOfTwo))
+	})
+
+	assert.Equal(t, "listen on PORT (1024..65535)", fs.Lookup("port").DescString())
+	assert.Equal(t, "block sizes (1..4096)", fs.Lookup("s").DescString())
+	assert.Equal(t, "percentages (0..100 by 5)", fs.Lookup("percent").DescString())
+
+	fs.Parse([]string{"-p", "8080", "-s", "512,1024", "-s8", "-l", "a=5s,b=1m",
+		"--name=web", "--point", "0.5", "1"})
+	assert.Equal(t, uint16(8080), port)
+	assert.Equal(t, []int{512, 1024, 8}, sizes)
+	assert.Equal(t, map[string]time.Duration{"a": 5 * time.Second, "b": time.Minute}, limits)
+	assert.Equal(t, "web", name)
+	assert.Equal(t, []float64{0.5, 1}, point)
+
+	err := fs.Lookup("port").Set("80", 1)
+	var verr *ValueError
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Equal(t, "invalid value '80' for flag '-p, --port': out of range 1024..65535", err.Error())
+	}
+	assert.ErrorContains(t, fs.Lookup("s").Set("16,24", 1), "24 is not a power of two")
+	assert.ErrorContains(t, fs.Lookup("s").Set("8192", 1), "out of range 1..4096")
+	assert.ErrorContains(t, fs.Lookup("l").Set("c=2h", 1), "out of range 1s..1m0s")
+	assert.NoError(t, fs.Lookup("l").Set("c=30s", 1))
+	assert.ErrorContains(t, fs.Lookup("name").Set("Web", 1), "does not match")
+	assert.ErrorContains(t, fs.Lookup("point").SetArgs([]string{"0", "2"}, 1), "out of range -1..1")
+	assert.Equal(t, uint16(8080), port)
+	assert.Equal(t, []int{512, 1024, 8}, sizes)
+	assert.Equal(t, "web", name)
+	assert.Equal(t, []float64{0.5, 1}, point)
+
+	assert.NoError(t, fs.Lookup("percent").Set("0,35,100", 1))
+	assert.ErrorContains(t, fs.Lookup("percent").Set("40,42", 1), "not in steps of 5 from 0")
+	assert.ErrorContains(t, fs.Lookup("percent").Set("105", 1), "out of range 0..100")
+	assert.Equal(t, []int{0, 35, 100}, percents)
+	assert.NoError(t, fs.Lookup("timeout").Set("46s", 1))
+	assert.Equal(t, 46*time.Second, timeout)
+	assert.ErrorContains(t, fs.Lookup("timeout").Set("30s", 1), "not in steps of 15s from 1s")
+}
+
+func TestIntLiteralFlags(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixEquals = true
+	PosixDoubleHyphen = true
+	PosixOperandStop = true
+	var mode uint32
+	var mask int
+	var count int64
+	var ids []uint16
+	var plain int
+	fs := NewFlagSet(WithSilentFail(), WithContinueOnFail())
+	fs.Var(&mode, 'm', "mode", "file mode", WithIntLiterals(true), WithDefault("0644"),
+		WithRepeats(false))
+	fs.Var(&mask, NoShort, "mask", "a mask", WithIntLiterals(false), WithRepeats(false))
+	fs.Var(&count, 'c', "count", "a count", WithIntUnits(), WithRepeats(false))
+	fs.Var(&ids, 'i', NoLong, "ids", WithIntLiterals(false))
+	fs.Var(&plain, 'p', NoLong, "plain")
+	assert.Panics(t, func() { fs.Var(new(string), NoShort, "str", "", WithIntLiterals(true)) })
+	assert.Panics(t, func() { fs.Var(new(float64), NoShort, "flt", "", WithIntUnits()) })
+
+	assert.Equal(t, uint32(0o644), mode)
+	assert.Equal(t, "0644", fs.Lookup("mode").GetValue())
+	assert.Equal(t, "0644(default)", fs.Lookup("mode").GetDefaultDescription())
+
+	fs.Parse([]string{"--mode=0755", "--mask=0x1ff", "-c", "10K", "-i", "0b101,1_000", "-p", "010"})
+	assert.Equal(t, uint32(0o755), mode)
+	assert.Equal(t, 0x1ff, mask)
+	assert.Equal(t, int64(10240), count)
+	assert.Equal(t, []uint16{5, 1000}, ids)
+	assert.Equal(t, 10, plain)
+	assert.Equal(t, "0755", fs.Lookup("mode").GetValue())
+	assert.Equal(t, "0x1ff", fs.Lookup("mask").GetValue())
+	assert.Equal(t, "10240", fs.Lookup("count").GetValue())
+	assert.Contains(t, fs.Args(LongArgStyle), "--mask=0x1ff")
+
+	assert.NoError(t, fs.Lookup("mask").Set("-0b11", 1))
+	assert.Equal(t, "-0b11", fs.Lookup("mask").GetValue())
+	assert.NoError(t, fs.Lookup("mask").Test("0o17", 1))
+	assert.Equal(t, "-0b11", fs.Lookup("mask").GetValue())
+	assert.NoError(t, fs.Lookup("mode").Set("0o600", 1))
+	assert.Equal(t, "0600", fs.Lookup("mode").GetValue())
+	assert.NoError(t, fs.Lookup("count").Set("2MB", 1))
+	assert.Equal(t, int64(2000000), count)
+
+	var verr *ValueError
+	assert.ErrorAs(t, fs.Lookup("mode").Set("0x1_0000_0000", 1), &verr)
+	assert.Error(t, fs.Lookup("p").Set("0x10", 1))
+	assert.Error(t, fs.Lookup("count").Set("0x10", 1))
+
+	// Values that are rejected or only tested don't change the base
+	ids = ids[:0]
+	assert.NoError(t, fs.Lookup('i').Set("0b1", 1))
+	assert.NoError(t, fs.Lookup('i').Test("0o7", 1))
+	assert.Error(t, fs.Lookup('i').Set("0x1_0000", 1))
+	assert.Equal(t, "0b1", fs.Lookup('i').GetValue())
+	var pair []int
+	fs.Var(&pair, 'P', NoLong, "a pair", WithNArgs(2), WithIntLiterals(false))
+	assert.NoError(t, fs.Lookup('P').SetArgs([]string{"1", "2"}, 1))
+	assert.Error(t, fs.Lookup('P').SetArgs([]string{"0x3", "x"}, 1))
+	assert.Equal(t, "1,2", fs.Lookup('P').GetValue())
+
+	fs.Reset()
+	assert.Equal(t, uint32(0o644), mode)
+	assert.Equal(t, "0644", fs.Lookup("mode").GetValue())
+	assert.Equal(t, 0, mask)
+}
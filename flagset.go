@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"strings"
+	"sync"
 	"unicode"
 
 	"github.com/EmmetCaulfield/fflag/pkg/deque"
@@ -44,6 +45,7 @@ var CommandLine *FlagSet = NewFlagSet()
 type FlagGroup struct {
 	Title string
 	FlagList          []*Flag
+	Operands          bool
 }
 
 // Creates a new `FlagGroup` given a title for the group.
@@ -59,15 +61,40 @@ func NewFlagGroup(title string) *FlagGroup {
 //
 // In the most commonly expected use case, there will be one `FlagSet`
 // for a program, the default `CommandLine`.
+//
+// Flags should all be defined before a `FlagSet` is shared between
+// goroutines. After that, `Parse()`, `Reset()`, and `Snapshot()` take
+// an exclusive lock, while `Args()`, `Clone()`, and `Get()` take a
+// shared lock, so they can safely be called concurrently. Reading
+// bound variables directly is only safe once parsing is known to be
+// complete. Since the package-level `Posix*` variables and
+// `CommandLine` are shared by everything in a program, a `FlagSet`
+// used concurrently should have its own `Dialect`.
+//
+// The lock is held while `Parse()` calls callbacks (see
+// `WithCallback()`), so a callback must not call `Parse()`,
+// `Reset()`, `Snapshot()`, `Args()`, `Clone()`, or `Get()` on the
+// `FlagSet` being parsed, which would deadlock. It can use the `Flag`
+// it is given, and the bound variables, directly.
 type FlagSet struct {
 	Groups             []*FlagGroup
 	GroupIndex         int
 	LongTrie          *trie.TrieNode[Flag]
 	ShortDict          map[rune]*Flag
 	Output             io.Writer
+	HelpOutput         io.Writer
+	Reporter           Reporter
+	ExitFunc           func(code int)
+	Quiet              bool
 	IgnoreDoubleDash   bool
 	HasHyphenNumIdiom  bool
 	HasNumberShorts    bool
+	PlusNumFlag        *Flag
+	LongW              bool
+	SingleDash         SingleDash
+	SingleDashAbbrev   bool
+	PlusOptions        bool
+	OperandKeys        *trie.TrieNode[Flag]
 	InputArgs         *deque.Deque[string]
 	OutputArgs        *deque.Deque[string]
 	OnFail             FailOption
@@ -75,6 +102,8 @@ type FlagSet struct {
 	OnFileError        FailOption
 	FileErrExitCode    int
 	Mutex              map[string]*Flag
+	Dialect            *Dialect
+	mu                 sync.RWMutex
 }
 
 // DefaultFailExitCode is the exit code that will be used when
@@ -129,6 +158,7 @@ func NewFlagSet(opts ...FlagSetOption) *FlagSet {
 		LongTrie:         trie.NewTrie[Flag](),
 		ShortDict:        map[rune]*Flag{},
 		Output:           os.Stderr,
+		HelpOutput:       os.Stdout,
 		IgnoreDoubleDash: false,
 		InputArgs:        &deque.Deque[string]{},
 		OutputArgs:       &deque.Deque[string]{},
@@ -153,7 +183,8 @@ func WithGroupTitle(title string) FlagSetOption {
 }
 
 // Option `WithOutputWriter()` sets the output writer for error
-// messages used if `OnFail` is not `Silent`.
+// messages used if `OnFail` is not `Silent`, and for warnings and
+// information unless the `FlagSet` is quiet (see `WithQuiet()`).
 func WithOutputWriter(w io.Writer) FlagSetOption {
 	return func(fs *FlagSet) {
 		fs.Output = w
@@ -161,26 +192,43 @@ func WithOutputWriter(w io.Writer) FlagSetOption {
 }
 
 // Option `WithPanicOnFail()` causes argument processing to panic on
-// any failure.
+// any failure, including a file error (see `OnFileError`).
 func WithPanicOnFail() FlagSetOption {
 	return func(fs *FlagSet) {
 		fs.OnFail.SetPanicBit()
+		fs.OnFileError.SetPanicBit()
 	}
 }
 
 // Option `WithContinueOnFail()` causes argument processing to
-// continue on failure, likely causing unpredictable results.
+// continue on failure, including a file error (see `OnFileError`),
+// likely causing unpredictable results.
 func WithContinueOnFail() FlagSetOption {
 	return func(fs *FlagSet) {
 		fs.OnFail.SetContinueBit()
+		fs.OnFileError.SetContinueBit()
 	}
 }
 
 // Option `WithSilentFail()` suppresses printing error messages due to
-// argument processing failure.
+// argument processing failure, including file errors (see
+// `OnFileError`).
 func WithSilentFail() FlagSetOption {
 	return func(fs *FlagSet) {
 		fs.OnFail.SetSilentBit()
+		fs.OnFileError.SetSilentBit()
+	}
+}
+
+// Option `WithLongW()` enables the GNU vendor extension reserved by
+// POSIX, where `-W foo=bar` (or `-Wfoo=bar`) is a synonym for
+// `--foo=bar`. The word following `-W` is looked up as a long flag,
+// including abbreviation, so `-W` can't also be defined as a short
+// flag. An `=` after `-W` (e.g. `-W=foo`) is ignored, and it is an
+// error for the word following a detached `-W` to be a flag or `--`.
+func WithLongW() FlagSetOption {
+	return func(fs *FlagSet) {
+		fs.LongW = true
 	}
 }
 
@@ -262,6 +310,12 @@ func (fs *FlagSet) AddFlag(f *Flag) error {
 	if !IsValidPair(f.Short, f.Long) {
 		return fmt.Errorf("flag '%s' has invalid short/long flags", f)
 	}
+	if fs.Group().Operands {
+		return fs.addOperandKey(f)
+	}
+	if err := fs.singleDashConflict(f); err != nil {
+		return err
+	}
 	if f.Long != NoLong {
 		err := fs.LongTrie.Add(f.Long, f)
 		if err != nil {
@@ -269,6 +323,9 @@ func (fs *FlagSet) AddFlag(f *Flag) error {
 		}
 	} 
 
+	if fs.LongW && f.Short == 'W' {
+		return fmt.Errorf("cannot define '-W' in -W mode")
+	}
 	if g, ok := fs.ShortDict[f.Short]; f.Short != NoShort && ok {
 		return fmt.Errorf("shortcut '%c' already used for '%s'", f.Short, g.Long)
 	}
@@ -291,6 +348,12 @@ func (fs *FlagSet) AddFlag(f *Flag) error {
 		}
 		fs.HasHyphenNumIdiom = true
 	}
+	if f.IsPlusNum() {
+		if fs.PlusNumFlag != nil {
+			return fmt.Errorf("cannot use +NUM idiom twice")
+		}
+		fs.PlusNumFlag = f
+	}
 	fs.Group().FlagList = append(fs.Group().FlagList, f)
 	return nil
 }
@@ -367,32 +430,35 @@ func (fs *FlagSet) DumpFlags() {
 	}
 }
 
+// Function `DumpUsage()` prints the usage text to the help writer
+// (see `WithHelpWriter()`), or gives it to the `Reporter`.
 func (fs *FlagSet) DumpUsage() {
-	fmt.Println(strings.Join(fs.AlignedFlagDescriptions("  ", "  ", ""), "\n"))
+	fs.reporter().Usage(strings.Join(fs.AlignedFlagDescriptions("  ", "  ", ""), "\n"))
 }
 
+// Function `Failf()` reports a command-line mistake (a `UsageError`)
+// and then continues, panics, or exits with `FailExitCode`, as
+// `OnFail` says.
 func (fs *FlagSet) Failf(format string, args ...interface{}) {
-	if !fs.OnFail.TstSilentBit() {
-		fmt.Fprintf(fs.Output, "ERROR: " + format + "\n", args...)
-	}
-	if fs.OnFail.TstContinueBit() {
-		return
-	}
-	if fs.OnFail.TstPanicBit() {
-		panic(fmt.Sprintf(format, args...))
-	}
-	os.Exit(fs.FailExitCode)
+	fs.fail(UsageError, fmt.Sprintf(format, args...))
+}
+
+// Function `FileFailf()` reports a failure to read a file (a
+// `FileError`) and then continues, panics, or exits with
+// `FileErrExitCode`, as `OnFileError` says.
+func (fs *FlagSet) FileFailf(format string, args ...interface{}) {
+	fs.fail(FileError, fmt.Sprintf(format, args...))
 }
 
 func (fs *FlagSet) Infof(format string, args ...interface{}) {
-	if !fs.OnFail.TstSilentBit() {
-		fmt.Fprintf(fs.Output, "INFO: " + format + "\n", args...)
+	if !fs.Quiet {
+		fs.reporter().Info(fmt.Sprintf(format, args...))
 	}
 }
 
 func (fs *FlagSet) Warnf(format string, args ...interface{}) {
-	if !fs.OnFail.TstSilentBit() {
-		fmt.Fprintf(fs.Output, "WARNING: " + format + "\n", args...)
+	if !fs.Quiet {
+		fs.reporter().Warning(fmt.Sprintf(format, args...))
 	}
 }
 
@@ -427,20 +493,37 @@ func (fs *FlagSet) AlignedFlagDescriptions(pre, mid, post string) []string {
 	return fstrs
 }
 
-// Function `Reset()` clears the input & output args, mutexes, and
-// counts while keeping the flag setup. It mostly exists for testing.
+// Function `Reset()` returns a `FlagSet` to its state before argument
+// processing while keeping the flag setup, so that it can be reused
+// (e.g. for successive commands in a REPL or in table-driven
+// tests). It clears the input & output args, mutexes, and counts, and
+// restores every bound value to what it was when the flag was created
+// (see `Flag.Reset()`), which is the default, if one was given with
+// `WithDefault()`, or whatever the variable held beforehand
+// otherwise. Slices are truncated, less any default.
 func (fs *FlagSet) Reset() {
-	// fmt.Fprintf(os.Stderr, "FlagSet has %d groups and %d mutexes\n", len(fs.Groups), len(fs.Mutex))
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
 	fs.InputArgs.Clear()
 	fs.OutputArgs.Clear()
 	for name, _ := range fs.Mutex {
 		fs.Mutex[name] = nil
 	}
 	for _, g := range fs.Groups {
-		// fmt.Fprintf(os.Stderr, "Visiting group '%s' with %d flags\n", g.Title, len(g.FlagList))
 		for _, f := range g.FlagList {
-			// fmt.Fprintf(os.Stderr, "Clearing flag '%s'\n", f)
-			f.Count = 0
+			f.Reset()
+		}
+	}
+}
+
+// Function `Snapshot()` records the current value of every flag in a
+// `FlagSet` as the value to be restored by `Reset()`.
+func (fs *FlagSet) Snapshot() {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	for _, g := range fs.Groups {
+		for _, f := range g.FlagList {
+			f.Snapshot()
 		}
 	}
 }
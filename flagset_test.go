@@ -0,0 +1,88 @@
+package fflag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReset(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixOperandStop = false
+	var a, b bool
+	var n int8
+	var s string
+	color := "never"
+	sa := []string{}
+	ia := []int{}
+	fs := NewFlagSet(WithSilentFail(), WithContinueOnFail())
+	fs.Var(&a, 'a', "ant", "six legs", InMutex("pet"))
+	fs.Var(&b, 'b', "bat", "two legs, two wings", WithDefault(true), InMutex("pet"))
+	fs.Var(&n, 'n', "number", "a number", WithDefault(int8(7)))
+	fs.Var(&s, 's', "snake", "no legs")
+	fs.Var(&color, NoShort, "color", "when to use color",
+		WithOptionalDefault([]string{"auto", "never", "always"}))
+	fs.Var(&sa, 'l', "list", "a list")
+	fs.Var(&ia, 'i', "ints", "some ints", WithDefault(42))
+
+	args := []string{"-a", "-n", "-5", "-s", "python", "--color", "-l", "x",
+		"-l", "y", "-i", "1", "operand"}
+	fs.Parse(args)
+	assert.Equal(t, true, a)
+	assert.Equal(t, int8(7), n, "-5 is not an optarg")
+	assert.Equal(t, "python", s)
+	assert.Equal(t, "auto", color)
+	assert.Equal(t, []string{"x", "y"}, sa)
+	assert.Equal(t, []int{42, 1}, ia)
+
+	fs.Reset()
+	assert.Equal(t, false, a)
+	assert.Equal(t, true, b)
+	assert.Equal(t, int8(7), n)
+	assert.Equal(t, "", s)
+	assert.Equal(t, "never", color, "optional default not applied")
+	assert.Equal(t, []string{}, sa)
+	assert.Equal(t, []int{42}, ia)
+	assert.Equal(t, 0, len(*fs.OutputArgs))
+
+	// The same arguments give the same result after a reset, including
+	// the mutex and non-repeatable scalars
+	fs.Parse([]string{"-b", "-s", "boa"})
+	assert.Equal(t, false, a)
+	assert.Equal(t, false, b)
+	assert.Equal(t, "boa", s)
+
+	fs.Reset()
+	fs.Parse([]string{"-a", "-s", "cobra"})
+	assert.Equal(t, true, a)
+	assert.Equal(t, true, b)
+	assert.Equal(t, "cobra", s)
+
+	// A snapshot changes what a reset restores
+	fs.Reset()
+	s = "adder"
+	fs.Snapshot()
+	fs.Parse([]string{"-s", "mamba"})
+	assert.Equal(t, "mamba", s)
+	fs.Reset()
+	assert.Equal(t, "adder", s)
+
+	// Values containing separators are restored as they were bound,
+	// and the same map and slice are refilled
+	kv := map[string]string{"k": "a,b"}
+	var list []string
+	fs = NewFlagSet(WithSilentFail(), WithContinueOnFail())
+	fs.Var(&kv, 'k', "kv", "pairs")
+	fs.Var(&list, 'i', "item", "items")
+	list = append(list, "x=y", "p,q")
+	fs.Snapshot()
+	m := kv
+	fs.Parse([]string{"-k", "k=c", "-k", "z=1", "-i", "r"})
+	assert.Equal(t, map[string]string{"k": "c", "z": "1"}, kv)
+	assert.NotPanics(t, fs.Reset)
+	assert.Equal(t, map[string]string{"k": "a,b"}, kv)
+	assert.Equal(t, map[string]string{"k": "a,b"}, m)
+	assert.Equal(t, []string{"x=y", "p,q"}, list)
+	assert.NotPanics(t, fs.Reset)
+	assert.Equal(t, map[string]string{"k": "a,b"}, kv)
+}
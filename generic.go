@@ -0,0 +1,182 @@
+package fflag
+
+import (
+	"log"
+	"reflect"
+	"sync"
+
+	"github.com/EmmetCaulfield/fflag/pkg/types"
+)
+
+// A `Parser` converts an option-argument to a value of type `T` (see
+// `Register()`).
+type Parser[T any] func(string) (T, error)
+
+// A `Formatter` renders a value of type `T` as an option-argument that
+// the corresponding `Parser` would accept (see `Register()`).
+type Formatter[T any] func(T) string
+
+type converter[T any] struct {
+	parse  Parser[T]
+	format Formatter[T]
+}
+
+var registry = struct {
+	sync.RWMutex
+	converters map[reflect.Type]interface{}
+}{converters: map[reflect.Type]interface{}{}}
+
+// Function `Register()` adds a parser and formatter for type `T` to
+// the type registry, so that flags of that type can be added with
+// `Add()` without `T` having to implement the `SetValue` interface.
+// A registered type takes precedence over the built-in handling of the
+// same type, so this can also be used to change how, say, a
+// `time.Time` is parsed. Registering a type again replaces its parser
+// and formatter.
+func Register[T any](parse Parser[T], format Formatter[T]) {
+	if parse == nil || format == nil {
+		log.Panicf("nil parser or formatter for %s", typeOf[T]())
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	registry.converters[typeOf[T]()] = converter[T]{parse, format}
+}
+
+// Function `Registered()` reports whether a parser and formatter have
+// been registered for type `T`.
+func Registered[T any]() bool {
+	_, ok := lookupConverter[T]()
+	return ok
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func lookupConverter[T any]() (converter[T], bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	c, ok := registry.converters[typeOf[T]()]
+	if !ok {
+		return converter[T]{}, false
+	}
+	return c.(converter[T]), true
+}
+
+// A `typedValue` adapts a registered type to the `SetValue`
+// interface.
+type typedValue[T any] struct {
+	ptr *T
+	converter[T]
+}
+
+func (v *typedValue[T]) Set(s string) error {
+	x, err := v.parse(s)
+	if err != nil {
+		return err
+	}
+	*v.ptr = x
+	return nil
+}
+
+func (v *typedValue[T]) String() string {
+	return v.format(*v.ptr)
+}
+
+func (v *typedValue[T]) target() interface{} {
+	return v.ptr
+}
+
+// A `TypedOption` is an option for a flag added with `Add()` that is
+// checked against the flag's type at compile time. It yields a
+// `FlagOption` given the formatter for the type, which is `nil` if the
+// type is handled by `fflag` itself rather than the type registry.
+type TypedOption[T any] func(format Formatter[T]) FlagOption
+
+// Typed option `Default()` is the equivalent of `WithDefault()` for a
+// flag added with `Add()`, except that a default of the wrong type is
+// a compile-time error rather than a `panic()`.
+func Default[T any](def T) TypedOption[T] {
+	return func(format Formatter[T]) FlagOption {
+		if format == nil {
+			return WithDefault(def)
+		}
+		return WithDefault(format(def))
+	}
+}
+
+// Typed option `Defaults()` is the equivalent of giving `WithDefault()`
+// a slice for a flag added with `Add()`: the first value is the
+// default and the values given are the only ones allowed.
+func Defaults[T any](def T, allowed ...T) TypedOption[T] {
+	return func(format Formatter[T]) FlagOption {
+		if format == nil {
+			return WithDefault(append([]T{def}, allowed...))
+		}
+		defs := []string{format(def)}
+		for _, a := range allowed {
+			defs = append(defs, format(a))
+		}
+		return WithDefault(defs)
+	}
+}
+
+// Typed option `OptionalDefault()` is the equivalent of
+// `WithOptionalDefault()` for a flag added with `Add()`.
+func OptionalDefault[T any](def T) TypedOption[T] {
+	return func(format Formatter[T]) FlagOption {
+		if format == nil {
+			return WithOptionalDefault(def)
+		}
+		return WithOptionalDefault(format(def))
+	}
+}
+
+// Typed option `Options()` passes ordinary options (e.g. `InMutex()`,
+// `WithTypeTag()`) to a flag added with `Add()`.
+func Options[T any](opts ...FlagOption) TypedOption[T] {
+	return func(Formatter[T]) FlagOption {
+		return func(f *Flag) error {
+			for _, opt := range opts {
+				if err := opt(f); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+}
+
+// Function `Add()` adds a flag of type `T` to a `FlagSet`, or to
+// `CommandLine` if `fs` is `nil`, like `Var()`. If a parser and
+// formatter have been registered for `T` (see `Register()`), they are
+// used to set and show the value, otherwise `T` must be one of the
+// types that `Var()` accepts. For example:
+//
+//	fflag.Register(mail.ParseAddress, (*mail.Address).String)
+//	from := &mail.Address{Address: "noreply@example.com"}
+//	fflag.Add(nil, &from, 'f', "from", "send mail from ADDRESS",
+//	    fflag.Options[*mail.Address](fflag.WithTypeTag("ADDRESS")))
+func Add[T any](fs *FlagSet, value *T, short rune, long string, usage string, opts ...TypedOption[T]) {
+	if fs == nil {
+		fs = CommandLine
+	}
+	if value == nil {
+		log.Panicf("nil value for flag -%c/--%s", short, long)
+	}
+	var target interface{} = value
+	var format Formatter[T]
+	valType := types.Type(value)
+	if c, ok := lookupConverter[T](); ok {
+		target = &typedValue[T]{value, c}
+		format = c.format
+	} else if valType.TstOtherBit() {
+		log.Panicf("type %s is neither registered nor supported for -%c/--%s",
+			typeOf[T](), short, long)
+	}
+	flagOpts := make([]FlagOption, len(opts))
+	for i, opt := range opts {
+		flagOpts[i] = opt(format)
+	}
+	fs.Var(target, short, long, usage, flagOpts...)
+}
@@ -0,0 +1,108 @@
+package fflag
+
+import (
+	"fmt"
+	"net/mail"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type genericTestLevel int
+
+func parseGenericTestLevel(s string) (genericTestLevel, error) {
+	switch s {
+	case "low":
+		return 1, nil
+	case "high":
+		return 2, nil
+	}
+	return 0, fmt.Errorf("unknown level '%s'", s)
+}
+
+func (l genericTestLevel) String() string {
+	return map[genericTestLevel]string{1: "low", 2: "high"}[l]
+}
+
+func TestAdd(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixEquals = true
+	PosixDoubleHyphen = true
+	PosixOperandStop = true
+	Register(parseGenericTestLevel, genericTestLevel.String)
+	Register(mail.ParseAddress, (*mail.Address).String)
+	assert.True(t, Registered[genericTestLevel]())
+	assert.False(t, Registered[struct{}]())
+
+	var n int
+	var names []string
+	var level genericTestLevel
+	var from *mail.Address
+	var opt genericTestLevel
+	fs := NewFlagSet(WithSilentFail(), WithContinueOnFail())
+	Add(fs, &n, 'n', "num", "a number", Default(3), Options[int](WithRepeats(false)))
+	Add(fs, &names, NoShort, "name", "names")
+	Add(fs, &level, 'l', "level", "a level", Default[genericTestLevel](1))
+	Add(fs, &from, 'f', "from", "send from ADDRESS",
+		Options[*mail.Address](WithTypeTag("ADDRESS")))
+	Add(fs, &opt, NoShort, "opt", "optional level", OptionalDefault[genericTestLevel](2))
+	assert.Panics(t, func() { Add(fs, new(struct{}), NoShort, "what", "") })
+	assert.Panics(t, func() { Add[int](fs, nil, NoShort, "nil", "") })
+
+	assert.Equal(t, 3, n)
+	assert.Equal(t, genericTestLevel(1), level)
+	assert.Equal(t, genericTestLevel(0), opt)
+	assert.Equal(t, "-f ADDRESS, --from=ADDRESS", fs.Lookup("from").FlagString())
+
+	fs.Parse([]string{"-n", "7", "--name=a,b", "--level=high", "-f", "Ann <ann@example.com>", "--opt"})
+	assert.Equal(t, 7, n)
+	assert.Equal(t, []string{"a", "b"}, names)
+	assert.Equal(t, genericTestLevel(2), level)
+	if assert.NotNil(t, from) {
+		assert.Equal(t, "ann@example.com", from.Address)
+	}
+	assert.Equal(t, genericTestLevel(2), opt)
+	assert.Contains(t, fs.Args(LongArgStyle), "--level=high")
+
+	assert.ErrorContains(t, fs.Lookup("level").Set("medium", 1), "unknown level 'medium'")
+	assert.Error(t, fs.Lookup("from").Set("not an address", 1))
+	assert.Equal(t, genericTestLevel(2), level)
+
+	// Registered types are restored by a reset
+	fs.Reset()
+	assert.Equal(t, genericTestLevel(1), level)
+	assert.Nil(t, from)
+	assert.Equal(t, genericTestLevel(0), opt)
+
+	// Enum-style defaults, of built-in and registered types
+	var color string
+	var mode genericTestLevel
+	Add(fs, &color, 'c', "color", "when to color", Defaults("auto", "always", "never"))
+	Add(fs, &mode, 'm', "mode", "a mode", Defaults[genericTestLevel](2, 1))
+	assert.Equal(t, "auto", color)
+	assert.Equal(t, genericTestLevel(2), mode)
+	assert.NoError(t, fs.Lookup("color").Set("never", 1))
+	assert.Error(t, fs.Lookup("color").Set("sometimes", 1))
+	assert.NoError(t, fs.Lookup("mode").Set("low", 1))
+	assert.Equal(t, genericTestLevel(1), mode)
+	var only genericTestLevel
+	Add(fs, &only, NoShort, "only", "only high", Defaults[genericTestLevel](2))
+	assert.Error(t, fs.Lookup("only").Set("low", 1))
+	assert.Equal(t, genericTestLevel(2), only)
+	fs.Reset()
+	assert.Equal(t, "auto", color)
+	assert.Equal(t, genericTestLevel(2), mode)
+
+	// A registered type takes precedence over the built-in handling
+	Register(func(s string) (uint8, error) { return uint8(len(s)), nil },
+		func(v uint8) string { return fmt.Sprint(v) })
+	defer func() {
+		registry.Lock()
+		delete(registry.converters, typeOf[uint8]())
+		registry.Unlock()
+	}()
+	var length uint8
+	Add(fs, &length, NoShort, "length", "length of the argument")
+	assert.NoError(t, fs.Lookup("length").Set("abcd", 1))
+	assert.Equal(t, uint8(4), length)
+}
@@ -3,6 +3,7 @@ module github.com/EmmetCaulfield/fflag
 go 1.21
 
 require (
+	github.com/spf13/pflag v1.0.5
 	github.com/stretchr/testify v1.9.0
 	golang.org/x/exp v0.0.0-20240325151524-a685a6edb6d8
 )
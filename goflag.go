@@ -0,0 +1,184 @@
+package fflag
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/EmmetCaulfield/fflag/pkg/types"
+)
+
+// A `GoFlagValue` wraps a `flag.Value` from the standard library's
+// `flag` package (or anything with the same methods, like a `pflag`
+// value) so that it can be the value of a `Flag`. It implements
+// `types.BoolSetValue`, so a wrapped boolean flag takes no
+// option-argument, as in the `flag` package, except one attached with
+// an `=` (e.g. `-b=false`), even to a short option.
+type GoFlagValue struct {
+	Value flag.Value
+}
+
+func (v *GoFlagValue) Set(s string) error {
+	return v.Value.Set(s)
+}
+
+func (v *GoFlagValue) String() string {
+	return v.Value.String()
+}
+
+func (v *GoFlagValue) IsBoolFlag() bool {
+	if bf, ok := v.Value.(interface{ IsBoolFlag() bool }); ok {
+		return bf.IsBoolFlag()
+	}
+	return false
+}
+
+// Function `GoFlagNames()` decides the short and long options for a
+// flag from another package having the given name: a single-rune
+// name becomes a short option and any other name a long option.
+func GoFlagNames(name string) (rune, string, error) {
+	if utf8.RuneCountInString(name) == 1 {
+		r, _ := utf8.DecodeRuneInString(name)
+		if (PosixRejectQuest && r == '?') || (PosixRejectW && r == 'W') || !IsValidShort(r) {
+			return NoShort, NoLong, fmt.Errorf("flag name '%s' is not a valid short option", name)
+		}
+		return r, NoLong, nil
+	}
+	if !IsValidLong(name) {
+		return NoShort, NoLong, fmt.Errorf("flag name '%s' is not a valid long option", name)
+	}
+	return NoShort, name, nil
+}
+
+// Function `ImportGoFlagSet()` registers every flag defined in a
+// standard library `flag.FlagSet` with a `FlagSet`, in the current
+// `FlagGroup`, so that flags defined by libraries using the `flag`
+// package can be given on a command line parsed by `fflag`. Since
+// the standard library doesn't distinguish short and long options, a
+// flag with a single-rune name becomes a short option and any other
+// flag a long option (see `GoFlagNames()`). The value is wrapped in a
+// `GoFlagValue`, so setting the `Flag` sets the original `flag.Value`,
+// and the placeholder name in backquotes in the usage (see
+// `flag.UnquoteUsage()`) becomes the type tag. The given options are
+// applied to every imported flag.
+//
+// An error is returned, and no more flags are imported, if a flag
+// name isn't valid as an option or is already in use.
+func (fs *FlagSet) ImportGoFlagSet(gfs *flag.FlagSet, opts ...FlagOption) error {
+	var err error
+	gfs.VisitAll(func(gf *flag.Flag) {
+		if err != nil {
+			return
+		}
+		err = fs.ImportValue(gf.Name, NoShort, gf.Value, gf.Usage, opts...)
+	})
+	return err
+}
+
+// Function `ImportGoFlagSet()` registers the flags of a standard
+// library `flag.FlagSet` (e.g. `flag.CommandLine`) with the default
+// `FlagSet`.
+func ImportGoFlagSet(gfs *flag.FlagSet, opts ...FlagOption) error {
+	return CommandLine.ImportGoFlagSet(gfs, opts...)
+}
+
+// Function `ImportValue()` registers a flag having a value with the
+// methods of a standard library `flag.Value` (such as a `pflag`
+// value) under the given name and, optionally, short option. It is
+// the common part of importing flags from other packages.
+func (fs *FlagSet) ImportValue(name string, short rune, value flag.Value, usage string, opts ...FlagOption) error {
+	s, long, err := GoFlagNames(name)
+	if err != nil {
+		return err
+	}
+	if short == NoShort {
+		short = s
+	} else if s != NoShort {
+		return fmt.Errorf("flag '%s' cannot have a single-rune name and a short option", name)
+	}
+	// Check before `NewFlag()`, which panics on finding a duplicate
+	if short != NoShort && fs.LookupShort(short) != nil {
+		return fmt.Errorf("short option '%c' already in use", short)
+	}
+	v := &GoFlagValue{Value: value}
+	tag, usage := flag.UnquoteUsage(&flag.Flag{Name: name, Usage: usage, Value: value})
+	if v.IsBoolFlag() {
+		tag = ""
+	}
+	options := append([]FlagOption{WithParent(fs), WithTypeTag(strings.ToUpper(tag))}, opts...)
+	f := NewFlag(v, short, long, usage, options...)
+	if f == nil {
+		return fmt.Errorf("failed to create flag '%s'", name)
+	}
+	return fs.AddFlag(f)
+}
+
+// A `goValue` adapts a `Flag` to the standard library's `flag.Value`
+// interface. It also has the `Type()` method of a `pflag` value.
+type goValue struct {
+	flag *Flag
+}
+
+func (v *goValue) Set(s string) error {
+	return v.flag.Set(s, 0)
+}
+
+func (v *goValue) String() string {
+	if v.flag == nil {
+		// The `flag` package makes zero values to check defaults
+		return ""
+	}
+	if sv, ok := v.flag.Value.(interface{ String() string }); ok && types.IsSetter(v.flag.Value) {
+		return sv.String()
+	}
+	return v.flag.GetValue()
+}
+
+// Counters take no argument either
+func (v *goValue) IsBoolFlag() bool {
+	return v.flag.IsBool() || v.flag.IsCounter()
+}
+
+func (v *goValue) Type() string {
+	if v.flag.IsBool() {
+		return "bool"
+	}
+	return strings.ToLower(v.flag.GetTypeTag())
+}
+
+// Function `GoValue()` returns a `flag.Value` that sets a `Flag` with
+// `Flag.Set()`, so that the `Flag`'s defaults, enums, callbacks, and
+// so on are respected. Failures are handled according to the `OnFail`
+// setting of the `Flag`'s `FlagSet` and are also returned.
+func (f *Flag) GoValue() flag.Value {
+	return &goValue{flag: f}
+}
+
+// Function `ExportGoFlagSet()` defines every flag in a `FlagSet` on a
+// standard library `flag.FlagSet`, so that a library can define its
+// flags with `fflag` while a program using it parses with the `flag`
+// package. A flag having both short and long options is defined under
+// both names, sharing the same value. Aliases are not exported.
+//
+// An error is returned if a name is already defined in the
+// `flag.FlagSet`, since the `flag` package would panic.
+func (fs *FlagSet) ExportGoFlagSet(gfs *flag.FlagSet) error {
+	for _, g := range fs.Groups {
+		for _, f := range g.FlagList {
+			if f.IsAlias() || f.IsHyphenNum() {
+				continue
+			}
+			for _, name := range []string{string(f.Short), f.Long} {
+				if name == string(NoShort) || name == NoLong {
+					continue
+				}
+				if gfs.Lookup(name) != nil {
+					return fmt.Errorf("flag '%s' already defined", name)
+				}
+				gfs.Var(f.GoValue(), name, f.Usage)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package fflag
+
+import (
+	"flag"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportGoFlagSet(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixEquals = true
+	PosixDoubleHyphen = true
+	PosixOperandStop = true
+
+	gfs := flag.NewFlagSet("lib", flag.ContinueOnError)
+	verbose := gfs.Bool("verbose", false, "be noisy")
+	quiet := gfs.Bool("q", true, "be quiet")
+	n := gfs.Int("n", 1, "number of things")
+	name := gfs.String("name", "anon", "use `NAME` for greetings")
+	timeout := gfs.Duration("timeout", time.Second, "wait this long")
+	xs := []string{}
+	gfs.Func("x", "an extra", func(s string) error {
+		xs = append(xs, s)
+		return nil
+	})
+
+	fs := NewFlagSet(WithSilentFail(), WithContinueOnFail())
+	assert.NoError(t, fs.ImportGoFlagSet(gfs, WithRepeats(false)))
+	assert.Equal(t, "-n INT", fs.Lookup('n').FlagString())
+	assert.Equal(t, "    --name=NAME", fs.Lookup("name").FlagString())
+	assert.Equal(t, "use NAME for greetings", fs.Lookup("name").Usage)
+	assert.Equal(t, "    --timeout=DURATION", fs.Lookup("timeout").FlagString())
+	assert.Equal(t, "    --verbose", fs.Lookup("verbose").FlagString())
+	assert.True(t, fs.Lookup("verbose").IsBool())
+	assert.False(t, fs.Lookup("name").IsBool())
+
+	fs.Parse([]string{"-n", "3", "--verbose", "-q", "--name=joe", "--timeout",
+		"2m", "-x", "foo", "-xbar", "--verbose", "--", "op"})
+	assert.Equal(t, true, *verbose)
+	assert.Equal(t, true, *quiet, "-q sets true, like the flag package")
+	assert.Equal(t, 3, *n)
+	assert.Equal(t, "joe", *name)
+	assert.Equal(t, 2*time.Minute, *timeout)
+	assert.Equal(t, []string{"foo", "bar"}, xs)
+	assert.Equal(t, []string{"op"}, []string(*fs.OutputArgs))
+
+	// A boolean takes an attached value, as in the `flag` package
+	errs := &strings.Builder{}
+	fs.Output = errs
+	fs.OnFail.ClrSilentBit()
+	fs.Parse([]string{"-q=false"})
+	assert.Equal(t, false, *quiet)
+	assert.Empty(t, errs.String())
+	*quiet = true
+	fs.Parse([]string{"-q=junk"})
+	assert.Contains(t, errs.String(), "ERROR: ")
+	fs.OnFail.SetSilentBit()
+
+	// Name conflicts and invalid names
+	assert.Error(t, fs.ImportGoFlagSet(gfs))
+	gfs = flag.NewFlagSet("lib", flag.ContinueOnError)
+	gfs.String("log_dir", "", "where to log")
+	assert.Error(t, fs.ImportGoFlagSet(gfs))
+	gfs = flag.NewFlagSet("lib", flag.ContinueOnError)
+	gfs.String("?", "", "help")
+	assert.Error(t, fs.ImportGoFlagSet(gfs))
+}
+
+func TestExportGoFlagSet(u *testing.T) {
+	t := assert.TestingT(u)
+	var verbose bool
+	var level uint
+	var snake string
+	var n int
+	ints := []int{}
+	fs := NewFlagSet(WithSilentFail(), WithContinueOnFail())
+	fs.Var(&verbose, 'v', "verbose", "be noisy", WithAlias(NoShort, "loud", false))
+	fs.Var(&level, 'l', NoLong, "how noisy", AsCounter())
+	fs.Var(&snake, 's', "snake", "a snake",
+		WithDefault([]string{"adder", "boa", "python"}))
+	fs.Var(&n, NoShort, "number", "a number", WithDefault(7))
+	fs.Var(&ints, 'i', NoLong, "some ints")
+
+	gfs := flag.NewFlagSet("prog", flag.ContinueOnError)
+	gfs.SetOutput(io.Discard)
+	assert.NoError(t, fs.ExportGoFlagSet(gfs))
+	assert.Nil(t, gfs.Lookup("loud"))
+	assert.Equal(t, "7", gfs.Lookup("number").DefValue)
+	assert.Equal(t, "adder", gfs.Lookup("s").DefValue)
+
+	err := gfs.Parse([]string{"-v", "-l", "-l", "-snake", "python", "-number=3",
+		"-i", "1", "-i", "2,3", "operand"})
+	assert.NoError(t, err)
+	assert.Equal(t, true, verbose)
+	assert.Equal(t, uint(2), level)
+	assert.Equal(t, "python", snake)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []int{7, 1, 2, 3}[1:], ints)
+	assert.Equal(t, []string{"operand"}, gfs.Args())
+	assert.Equal(t, 1, fs.Lookup("snake").Count)
+
+	// Failures are returned to the flag package
+	assert.Error(t, gfs.Parse([]string{"-s", "cobra"}))
+	assert.Equal(t, "python", snake)
+
+	// Names already defined
+	assert.Error(t, fs.ExportGoFlagSet(gfs))
+
+	usage := &strings.Builder{}
+	gfs.SetOutput(usage)
+	gfs.PrintDefaults()
+	assert.Contains(t, usage.String(), "-verbose\n")
+	assert.Contains(t, usage.String(), "a snake (default adder)")
+}
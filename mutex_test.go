@@ -16,3 +16,18 @@ func TestMutexes(t *testing.T) {
 	CommandLine.Parse([]string{"-d"})
 	assert.Equal(t, false, d)
 }
+
+func TestRepeatedMutex(t *testing.T) {
+	r := &testReporter{}
+	var v, q int
+	fs := NewFlagSet(WithReporter(r), WithContinueOnFail())
+	fs.Var(&v, 'v', "verbose", "be noisy", AsCounter(), InMutex("noise"))
+	fs.Var(&q, 'q', "quiet", "be quiet", AsCounter(), InMutex("noise"))
+	// A flag doesn't conflict with itself when it is repeated
+	fs.Parse([]string{"-v", "-v"})
+	assert.Equal(t, 2, v)
+	assert.Empty(t, r.messages)
+	fs.Parse([]string{"-q"})
+	assert.Equal(t, 0, q)
+	assert.Len(t, r.messages, 1)
+}
@@ -0,0 +1,89 @@
+package fflag
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/EmmetCaulfield/fflag/pkg/trie"
+)
+
+// Function `NewOperandGroup()` creates a new titled flag group whose
+// flags are given as `key=value` operands, as in `dd if=FILE bs=4K`,
+// rather than as options, and makes it the default `FlagGroup` to
+// which subsequent flags will be added. The long flag is the key,
+// which can be abbreviated to any unique prefix, and there can be no
+// short flag. The value is set just as an option-argument would be,
+// so keys have the same types, defaults, and constraints as flags. An
+// operand whose key isn't defined remains an operand.
+func (fs *FlagSet) NewOperandGroup(title string) *FlagGroup {
+	fg := fs.NewFlagGroup(title)
+	fg.Operands = true
+	return fg
+}
+
+// Function `OperandGroup()` creates a new titled operand group (see
+// `NewOperandGroup()`) in the default `FlagSet`.
+func OperandGroup(title string) {
+	_ = CommandLine.NewOperandGroup(title)
+}
+
+// Function `addOperandKey()` adds a flag to the current group when
+// that is an operand group.
+func (fs *FlagSet) addOperandKey(f *Flag) error {
+	if f.Short != NoShort || f.Long == NoLong {
+		return fmt.Errorf("operand '%s' must have a key and no short flag", f)
+	}
+	if f.HasNArgs() || f.IsHyphenNum() || f.IsPlusNum() {
+		return fmt.Errorf("operand '%s' can only take a single value", f)
+	}
+	if strings.Contains(f.Long, "=") {
+		return fmt.Errorf("operand key '%s' cannot contain '='", f.Long)
+	}
+	if fs.OperandKeys == nil {
+		fs.OperandKeys = trie.NewTrie[Flag]()
+	}
+	err := fs.OperandKeys.Add(f.Long, f)
+	if err != nil {
+		return fmt.Errorf("error adding operand key '%s': %w", f.Long, err)
+	}
+	f.operand = true
+	fs.Group().FlagList = append(fs.Group().FlagList, f)
+	return nil
+}
+
+// Function `LookupKey()` looks up the key of a `key=value` operand
+// (see `NewOperandGroup()`). If the string is a unique prefix match
+// for a key, a pointer to the corresponding `Flag` is returned,
+// otherwise `nil` is returned.
+func (fs *FlagSet) LookupKey(key string) *Flag {
+	if fs.OperandKeys == nil || key == "" {
+		return nil
+	}
+	f, err := fs.OperandKeys.Get(key)
+	if err != nil {
+		return nil
+	}
+	return f
+}
+
+func (f *Flag) IsOperand() bool {
+	return f.operand
+}
+
+// Function `setOperand()` sets the flag for a `key=value` operand,
+// returning `false` if it isn't one.
+func (fs *FlagSet) setOperand(arg string, pos int) bool {
+	key, value, ok := strings.Cut(arg, "=")
+	if !ok {
+		return false
+	}
+	f := fs.LookupKey(key)
+	if f == nil {
+		return false
+	}
+	err := f.Set(value, pos)
+	if err != nil {
+		fs.failSet(err, "failed to set operand '%s' with '%s': %v", f.Long, value, err)
+	}
+	return true
+}
@@ -10,6 +10,9 @@ import (
 	"strconv"
 	"strings"
 	"unicode"
+
+	"github.com/EmmetCaulfield/fflag/pkg/shlex"
+	"github.com/EmmetCaulfield/fflag/pkg/types"
 )
 
 // A flag argument can be:
@@ -22,13 +25,13 @@ import (
 //
 //   * `--flag`          --flag()       : prohibited/optional
 //   * `--flag=value`    --flag(value)  : optional/required
-//   * `--flag value`    --flag(value)  : required/optional
+//   * `--flag value`    --flag(value)  : required/optional (if `OptionalDetachedLong`)
 //   * `--flag operand`  --flag()       : prohibited/optional
 //   * `-f`              -f()           : prohibited/optional
 //   * `-f opd`          -f()           : prohibited/optional
 //   * `-f=arg`          -f(=arg)       : optional/required (POSIX)
 //   * `-f=arg`          -f(arg)        : optional/required (GNU-ish de-facto, non-POSIX)
-//   * `-f arg`          -f(arg)        : optional/required
+//   * `-f arg`          -f(arg)        : required/optional (if `OptionalDetachedShort`)
 //   * `-farg`           -f(arg)        : optional/required
 //   * `-fgh`            -f() -g() -h() :
 //   * `-fgh`            -f() -g(h)     :
@@ -47,6 +50,8 @@ import (
 //   * followed by another long flag argument
 //   * followed by a shortcut flag
 //   * followed by a non-flag argument
+//
+// `TestArgumentGrid` checks how each is interpreted.
 
 type ArgMask int8
 
@@ -58,6 +63,7 @@ const (
 	AMParamBit           = 0b00001000 // The argument has an attached parameter (--flag=param)
 	AMHyphenBit          = 0b00010000 // The argument is just hyphens ("-" or "--")
 	AMNumberBit          = 0b00100000 // The argument is a number
+	AMPlusBit            = 0b01000000 // The argument starts with a plus ("+x")
 )
 
 func (am *ArgMask) String() string {
@@ -70,18 +76,21 @@ func (am *ArgMask) SetClusterBit()      { *am = *am | AMClusterBit }
 func (am *ArgMask) SetParamBit()        { *am = *am | AMParamBit }
 func (am *ArgMask) SetHyphenBit()       { *am = *am | AMHyphenBit }
 func (am *ArgMask) SetNumberBit()       { *am = *am | AMNumberBit }
+func (am *ArgMask) SetPlusBit()         { *am = *am | AMPlusBit }
 func (am *ArgMask) ClrFlagBit()         { *am = *am & ^AMFlagBit }
 func (am *ArgMask) ClrLongBit()         { *am = *am & ^AMLongBit }
 func (am *ArgMask) ClrClusterBit()      { *am = *am & ^AMClusterBit }
 func (am *ArgMask) ClrParamBit()        { *am = *am & ^AMParamBit }
 func (am *ArgMask) ClrHyphenBit()       { *am = *am & ^AMHyphenBit }
 func (am *ArgMask) ClrNumberBit()       { *am = *am & ^AMNumberBit }
+func (am *ArgMask) ClrPlusBit()         { *am = *am & ^AMPlusBit }
 func (am *ArgMask) TstFlagBit() bool    { return *am&AMFlagBit != 0 }
 func (am *ArgMask) TstLongBit() bool    { return *am&AMLongBit != 0 }
 func (am *ArgMask) TstClusterBit() bool { return *am&AMClusterBit != 0 }
 func (am *ArgMask) TstParamBit() bool   { return *am&AMParamBit != 0 }
 func (am *ArgMask) TstHyphenBit() bool  { return *am&AMHyphenBit != 0 }
 func (am *ArgMask) TstNumberBit() bool  { return *am&AMNumberBit != 0 }
+func (am *ArgMask) TstPlusBit() bool    { return *am&AMPlusBit != 0 }
 
 // Tests if an argument mask represents any kind of flag
 func (am *ArgMask) IsFlag() bool {
@@ -195,9 +204,14 @@ func parseSingleArg(arg string) (flags string, param string, argType ArgMask) {
 		argType.ClrLongBit()
 		flag = arg[1:len(arg)]
 	} else {
-		// Not a flag, must be a param
+		// Not a flag, must be a param, but it may be a plus option
+		// (see `WithPlusOptions()`)
 		// argType.ClrFlagBit()
 		// flags = []string{}
+		if arg[0] == '+' {
+			argType.SetPlusBit()
+			flags = arg[1:]
+		}
 		param = arg
 		return
 	}
@@ -239,7 +253,9 @@ func parseSingleArg(arg string) (flags string, param string, argType ArgMask) {
 		if unicode.IsNumber(r) {
 			argType.SetNumberBit()
 		}
-		flags = string(r)
+		// Not `string(r)`, which would turn "-=x" or invalid UTF-8
+		// into a flag that wasn't given
+		flags = flag
 		return
 	}
 
@@ -269,14 +285,45 @@ func parseSingleArg(arg string) (flags string, param string, argType ArgMask) {
 //   * f(gh)
 //
 // We work from left-to-right, giving precedence to interpretation as
-// a flag. Once a non-flag is encountered, the rest of the string is
-// assumed to be an option-argument to the last flag.
-func (fs *FlagSet) disambiguateCluster(flags string, param string, argType ArgMask, pos int) *Flag {
+// a flag, unless the last flag requires an option-argument. Once a
+// non-flag is encountered, the rest of the string is assumed to be an
+// option-argument to the last flag. The number of
+// following arguments also consumed, by a flag taking several
+// option-arguments, is returned with the last flag.
+func (fs *FlagSet) disambiguateCluster(flags string, param string, argType ArgMask, pos int) (*Flag, int) {
 	// Process clusters by POSIX rules where the last flag in
 	// the cluster can have an option-argument.
+	if fs.HasHyphenNumIdiom {
+		if n := leadingDigits(flags); n > 0 && n < len(flags) {
+			return fs.hyphenNumCluster(flags, n, param, argType, pos)
+		}
+	}
 	var curr *Flag
 	for i, s := range flags {
 		prev := curr
+		if prev != nil && prev.requiresArg() {
+			// As in `getopt()`, the rest of the cluster is a required
+			// option-argument, even if it looks like flags. An
+			// optional one has to be a non-flag, as below.
+			return fs.setClusterArg(prev, flags[i:], param, argType, pos)
+		}
+		if fs.LongW && s == 'W' {
+			if prev != nil {
+				err := prev.Set(nil, pos)
+				if err != nil {
+					fs.failSet(err, "failed to set '%s' with nil: %v", prev, err)
+				}
+			}
+			// The `=` of `-W=long` isn't part of the long flag
+			long := flags[i+1:]
+			attached := long != "" || argType.HasParam()
+			if argType.HasParam() && long == "" {
+				long = param
+			} else if argType.HasParam() {
+				long += "=" + param
+			}
+			return nil, fs.longW(long, attached)
+		}
 		curr = fs.Lookup(s)
 		if curr == nil {
 			// Could be a number:
@@ -285,35 +332,162 @@ func (fs *FlagSet) disambiguateCluster(flags string, param string, argType ArgMa
 				if curr != nil {
 					err := curr.Set(flags, pos)
 					if err != nil {
-						fs.Failf("failed to set '%s' with '%s' (-NUM idiom): %v", curr, flags, err)
+						fs.failSet(err, "failed to set '%s' with '%s' (-NUM idiom): %v", curr, flags, err)
 					}
-					return nil
+					return nil, 0
 				}
 			}
+			if prev == nil {
+				fs.Failf("flag '-%c' not defined in '-%s'", s, flags)
+				return nil, 0
+			}
 			// Non-flag: this and whatever follows must be an attached
 			// option-argument to the previous flag
-			optarg := flags[i:]
-			if param != "" {
-				optarg += "=" + param
-			}
-			err := prev.Set(optarg, pos)
-			if err != nil {
-				// We may return (or not) after Fail depending on OnFail setting
-				fs.Failf("failed to set '%s' with '%s': %v", prev, optarg, err)
-			}
-			return nil
+			return fs.setClusterArg(prev, flags[i:], param, argType, pos)
 		}
 		if prev != nil {
 			err := prev.Set(nil, pos)
 			if err != nil {
-				fs.Failf("failed to set '%s' with nil: %v", prev, err)
+				fs.failSet(err, "failed to set '%s' with nil: %v", prev, err)
 			}
 		}
 	}
 	// We now have the last flag in `curr` that hasn't been acted on:
 	// return it in case there's an unattached option-argument (aka
 	// parameter) in the next argument
-	return curr
+	return curr, 0
+}
+
+// Function `setClusterArg()` sets a flag with the rest of the cluster
+// in which it appears, and any parameter attached with an '='.
+func (fs *FlagSet) setClusterArg(flag *Flag, optarg string, param string, argType ArgMask, pos int) (*Flag, int) {
+	if argType.HasParam() {
+		optarg += "=" + param
+	}
+	if flag.HasNArgs() {
+		return nil, fs.setArgs(flag, []string{optarg}, pos)
+	}
+	err := flag.Set(optarg, pos)
+	if err != nil {
+		// We may return (or not) after Fail depending on OnFail setting
+		fs.failSet(err, "failed to set '%s' with '%s': %v", flag, optarg, err)
+	}
+	return nil, 0
+}
+
+// Function `hyphenNumCluster()` handles the -NUM idiom at the start of
+// a cluster, where the `n` leading digits are followed either by short
+// flags (e.g. `tail -5f`) or by something else, which is taken to be
+// part of the number (e.g. the suffix in `-5K`).
+func (fs *FlagSet) hyphenNumCluster(flags string, n int, param string, argType ArgMask, pos int) (*Flag, int) {
+	num, rest := flags[:n], flags[n:]
+	r, _ := FirstRune(rest)
+	if fs.Lookup(r) == nil {
+		num, rest = flags, ""
+	}
+	target := fs.Lookup(NoShort)
+	err := target.Set(num, pos)
+	if err != nil {
+		fs.failSet(err, "failed to set '%s' with '%s' (-NUM idiom): %v", target, num, err)
+	}
+	if rest == "" {
+		if argType.HasParam() {
+			fs.Failf("unexpected option-argument '%s' for '-%s' (-NUM idiom)", param, flags)
+		}
+		return nil, 0
+	}
+	return fs.disambiguateCluster(rest, param, argType, pos)
+}
+
+// Function `longW()` handles `-W` in -W mode (see `WithLongW()`) by
+// putting the long flag it stands for, which is either attached or the
+// next argument, back in the input as `--long`. It returns the
+// adjustment to the argument position: -1 if an attached long flag was
+// put back, since it will be counted again. A next argument that is
+// itself a flag, or `--`, is left alone, since `-W` can't take it.
+func (fs *FlagSet) longW(long string, attached bool) int {
+	if attached {
+		if long == "" {
+			fs.Failf("flag '-W' requires a long flag")
+			return 0
+		}
+		fs.InputArgs.Unshift("--" + long)
+		return -1
+	}
+	next, err := fs.InputArgs.Front()
+	if err != nil {
+		fs.Failf("flag '-W' requires a long flag")
+		return 0
+	}
+	if strings.HasPrefix(next, "-") {
+		fs.Failf("flag '-W' requires a long flag, not '%s'", next)
+		return 0
+	}
+	_, _ = fs.InputArgs.Shift()
+	fs.InputArgs.Unshift("--" + next)
+	return 0
+}
+
+// Function `leadingDigits()` returns the number of decimal digits at
+// the start of a string.
+func leadingDigits(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
+
+// Function `isPlusNum()` reports whether an argument is a plus sign
+// followed by a number (the +NUM idiom).
+func isPlusNum(arg string) bool {
+	return len(arg) > 1 && arg[0] == '+' && leadingDigits(arg[1:]) > 0
+}
+
+// Function `isNegativeNum()` reports whether an argument that looks
+// like a flag could be a negative number.
+func isNegativeNum(arg string) bool {
+	return len(arg) > 1 && arg[0] == '-' && (leadingDigits(arg[1:]) > 0 || arg[1] == '.')
+}
+
+// Function `takesNegativeNum()` reports whether the flag will take an
+// argument that looks like a flag as a negative number (see
+// `WithNegativeArgs()`).
+func (fs *FlagSet) takesNegativeNum(flag *Flag, arg string, pos int) bool {
+	return isNegativeNum(arg) && flag.takesNegativeArgs() && flag.Test(arg, pos) == nil
+}
+
+// Function `setArgs()` sets a flag taking several option-arguments
+// with those already found (attached) and as many of the following
+// arguments as it takes, stopping at anything that looks like an
+// option or a double-hyphen. It returns the number of following
+// arguments consumed.
+func (fs *FlagSet) setArgs(flag *Flag, args []string, pos int) int {
+	n := 0
+	for len(args) < flag.MaxArgs {
+		next, err := fs.InputArgs.Front()
+		if err != nil {
+			break
+		}
+		_, param, argType := parseSingleArg(next)
+		if argType.IsDoubleHyphen() {
+			break
+		}
+		if argType.IsFlag() {
+			if !isNegativeNum(next) || !flag.takesNegativeArgs() || flag.validateItem(next) != nil {
+				break
+			}
+			param = next
+		}
+		args = append(args, param)
+		_, _ = fs.InputArgs.Shift()
+		n++
+	}
+	err := flag.SetArgs(args, pos)
+	if err != nil {
+		fs.failSet(err, "failed to set flag `%s` with %q: %v", flag, args, err)
+	}
+	return n
 }
 
 // Function StopParsing moves all remaining input arguments to the
@@ -329,34 +503,60 @@ func (fs *FlagSet) stopParsing(shift bool) {
 func (fs *FlagSet) parse() error {
 	var err error
 	var i int = 0
+	dialect := fs.dialect()
 
 	for arg, err := fs.InputArgs.Shift(); err == nil; arg, err = fs.InputArgs.Shift() {
 		i++
 		flags, param, argType := parseSingleArg(arg)
+		if argType.IsDoubleHyphen() {
+			// arg can't be an option-argument at this point, so we
+			// terminate processing under either POSIX or GNU rules
+			fs.stopParsing(false)
+			return nil
+		}
 		if !argType.IsFlag() {
+			if fs.PlusNumFlag != nil && isPlusNum(param) {
+				err = fs.PlusNumFlag.Set(param[1:], i)
+				if err != nil {
+					fs.failSet(err, "failed to set '%s' with '%s' (+NUM idiom): %v", fs.PlusNumFlag, param, err)
+				}
+				continue
+			}
+			if fs.PlusOptions && argType.TstPlusBit() {
+				fs.unsetFlags(flags, i)
+				continue
+			}
+			if fs.OperandKeys != nil && fs.setOperand(param, i) {
+				continue
+			}
 			fs.OutputArgs.Push(param)
-			if PosixOperandStop {
+			if dialect.OperandStop {
 				fs.stopParsing(false)
 				return nil
 			}
 			continue
 		}
-		if argType.IsDoubleHyphen() {
-			// arg can't be an option-argument at this point, so we
-			// terminate processing under either POSIX or GNU rules
-			fs.stopParsing(false)
-			return nil
+		if argType.IsCluster() && fs.singleDashLong(flags) {
+			argType.SetLongBit()
+			argType.ClrClusterBit()
+			argType.ClrNumberBit()
 		}
 		var flag *Flag = nil
 		if argType.IsCluster() {
 			// It's parsed as a cluster, but that doesn't mean it
 			// is. It could be a flag with an attached argument.
-			flag = fs.disambiguateCluster(flags, param, argType, i)
+			var n int
+			flag, n = fs.disambiguateCluster(flags, param, argType, i)
+			i += n
 			if flag == nil {
 				// Fully handled in fs.disambiguateCluster()
 				continue
 			}
 		} else {
+			if fs.LongW && flags == "W" {
+				i += fs.longW(param, argType.HasParam())
+				continue
+			}
 			flag = fs.Lookup(flags)
 			if flag == nil {
 				if !argType.IsNumber() {
@@ -370,24 +570,38 @@ func (fs *FlagSet) parse() error {
 				}
 				err = flag.Set(flags, i)
 				if err != nil {
-					fs.Failf("failed to set -NUM flag with '%s': %v", flags, err)
+					fs.failSet(err, "failed to set -NUM flag with '%s': %v", flags, err)
 				}
 				continue
 			}
 		}
+		if flag.HasNArgs() {
+			args := []string{}
+			if argType.HasParam() {
+				if (argType.IsShortFlag() || argType.IsCluster()) && dialect.Equals {
+					param = "=" + param
+				}
+				args = append(args, param)
+			}
+			i += fs.setArgs(flag, args, i)
+			continue
+		}
 		if argType.HasParam() {
 			// This must've been attached with an '=', so if it's a
 			// short flag, the '=' is part of the argument under POSIX
 			// rules and it we don't have to check if the flag takes
 			// an argument: if it fails, there's a mistake on the
-			// command-line.
-			if (argType.IsShortFlag() || argType.IsCluster()) && PosixEquals {
+			// command-line. The exception is a value like a boolean
+			// from the `flag` package, which follows its `-b=false`
+			// convention.
+			if (argType.IsShortFlag() || argType.IsCluster()) && dialect.Equals &&
+				!types.IsBoolSetter(flag.Value) {
 				err = flag.Set("="+param, i)
 			} else {
 				err = flag.Set(param, i)
 			}
 			if err != nil {
-				fs.Failf("failed to set flag `%s` with '%s': %v", flag.String(), param, err)
+				fs.failSet(err, "failed to set flag `%s` with '%s': %v", flag.String(), param, err)
 			}
 			continue
 		}
@@ -398,58 +612,95 @@ func (fs *FlagSet) parse() error {
 			// End of InputArgs
 			err = flag.Set(nil, i)
 			if err != nil {
-				fs.Failf("failed to set flag `%s` at EOL with no parameter: %v", flag.String(), err)
+				fs.failSet(err, "failed to set flag `%s` at EOL with no parameter: %v", flag.String(), err)
 			}
 			// At EOL
 			return nil
 		}
-		// Have next arg, might be a parameter
+		// Have next arg, might be a parameter, but not if the flag's
+		// option-argument is optional and must be attached
+		attachedOnly := flag.Type.TstDefOptionalBit() && !dialect.optionalDetached(argType)
+		nullary := flag.IsBool() || flag.IsCounter() || attachedOnly
 		flags, param, nextArgType := parseSingleArg(next)
-		if !nextArgType.IsFlag() {
+		if !nextArgType.IsFlag() && !fs.isPlusOption(flags, nextArgType) {
 			if nextArgType.IsDoubleHyphen() {
 				// Under GNU (not POSIX) rules, we terminate if the
-				// double-hyphen appears anywhere:
-				if !PosixDoubleHyphen {
-					fs.stopParsing(true)
-					return nil
-				}
-				// See if the flag will accept "--" as an argument:
-				err = flag.Test("--", i)
-				if err != nil {
-					// flag wouldn't eat it, so not an option-argument
+				// double-hyphen appears anywhere, otherwise we see if
+				// the flag will accept "--" as an argument. Either
+				// way, if it isn't an option-argument, a flag that
+				// doesn't need one is still set.
+				if !dialect.DoubleHyphen || nullary || flag.Test("--", i) != nil {
+					if nullary || flag.Type.TstDefOptionalBit() {
+						err = flag.Set(nil, i)
+						if err != nil {
+							fs.failSet(err, "failed to set flag `%s` with no parameter", flag.String())
+						}
+					}
 					fs.stopParsing(true)
 					return nil
 				}
 				err = flag.Set("--", i)
 				if err != nil {
-					fs.Failf("failed to set flag `%s` with `--` after Test(): %v", flag, err)
+					fs.failSet(err, "failed to set flag `%s` with `--` after Test(): %v", flag, err)
 				}
 				// It worked as a parameter/optarg, so consume it
 				_, _ = fs.InputArgs.Shift()
 				i++
 				continue
 			}
-			// Not a flag, try it as a parameter
-			if !flag.IsBool() {
+			// Not a flag, try it as a parameter. A counter would
+			// accept anything, so it mustn't be tried.
+			if !nullary {
+				if flag.Type.TstDefOptionalBit() && flag.Test(param, i) != nil {
+					// Not an optional option-argument either
+					err = flag.Set(nil, i)
+					if err != nil {
+						fs.failSet(err, "failed to set flag `%s` with no parameter", flag.String())
+					}
+					continue
+				}
+				// It's the option-argument, so consume it, unless
+				// it's required and couldn't be set, in which case
+				// it's left as an operand
 				err = flag.Set(param, i)
-				if err == nil {
-					// It worked as a parameter, so consume it
-					_, _ = fs.InputArgs.Shift()
-					i++
+				if err != nil {
+					fs.failSet(err, "failed to set flag `%s` with '%s': %v", flag, param, err)
+					if !flag.Type.TstDefOptionalBit() {
+						continue
+					}
 				}
+				_, _ = fs.InputArgs.Shift()
+				i++
 				continue
 			}
 		}
+		if fs.takesNegativeNum(flag, next, i) {
+			err = flag.Set(next, i)
+			if err != nil {
+				fs.failSet(err, "failed to set flag `%s` with '%s': %v", flag, next, err)
+			}
+			_, _ = fs.InputArgs.Shift()
+			i++
+			continue
+		}
 		// Next arg is a flag, current flag has no parameter
 		err = flag.Set(nil, i)
 		if err != nil {
-			fs.Failf("failed to set flag `%s` with no parameter", flag.String())
+			fs.failSet(err, "failed to set flag `%s` with no parameter", flag.String())
 		}
 	}
 	return err
 }
 
+// Function `Parse()` processes the given arguments according to the
+// flag definitions in the `FlagSet`. It holds the `FlagSet`'s lock
+// throughout, so concurrent calls on the same `FlagSet` are
+// serialized rather than interleaved, but distinct `FlagSet`s (see
+// `Clone()`) can be parsed in parallel. Callbacks are called with the
+// lock held (see `FlagSet`).
 func (fs *FlagSet) Parse(arguments []string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
 	fs.InputArgs.Init(arguments...)
 	return fs.parse()
 }
@@ -457,3 +708,24 @@ func (fs *FlagSet) Parse(arguments []string) error {
 func Parse() {
 	CommandLine.Parse(os.Args[1:])
 }
+
+// Function `ParseString()` parses arguments from a whole command line
+// held as a single string, split into arguments with POSIX shell
+// quoting rules (see `shlex.Split()`). Pass `shlex.WithEnv()` to
+// enable `$VAR` expansion. A malformed string isn't parsed at all,
+// nor is it reported as a failure, whatever `OnFail` says: the
+// returned error is a `*shlex.SyntaxError` giving the offset of the
+// problem, for the caller to deal with.
+func (fs *FlagSet) ParseString(cmd string, opts ...shlex.Option) error {
+	arguments, err := shlex.Split(cmd, opts...)
+	if err != nil {
+		return err
+	}
+	return fs.Parse(arguments)
+}
+
+// Function `ParseString()` parses arguments from a single string in
+// the default `FlagSet`.
+func ParseString(cmd string, opts ...shlex.Option) error {
+	return CommandLine.ParseString(cmd, opts...)
+}
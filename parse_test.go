@@ -1,9 +1,11 @@
 package fflag
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/EmmetCaulfield/fflag/pkg/deque"
+	"github.com/EmmetCaulfield/fflag/pkg/shlex"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -62,6 +64,55 @@ func TestPosixDoubleHyphen(u *testing.T) {
 	assert.Equal(t, expected, fs.OutputArgs, "POSIX rule")
 }
 
+func TestDoubleHyphenAfterNullary(u *testing.T) {
+	t := assert.TestingT(u)
+	var a bool
+	var v int
+	color := "never"
+	fs := NewFlagSet()
+	fs.Var(&a, 'a', "ant", "six legs")
+	fs.Var(&v, 'v', "verbose", "be noisy", AsCounter())
+	fs.Var(&color, NoShort, "color", "when to use color",
+		WithOptionalDefault([]string{"auto", "never", "always"}))
+
+	for _, posix := range []bool{false, true} {
+		PosixDoubleHyphen = posix
+		for _, arg := range []string{"-a", "-v", "--color"} {
+			fs.Reset()
+			fs.Parse([]string{arg, "--", "-a"})
+			assert.Equal(t, []string{"-a"}, []string(*fs.OutputArgs))
+		}
+		assert.Equal(t, "auto", color)
+		fs.Parse([]string{"-a", "-v", "--"})
+		assert.Equal(t, true, a)
+		assert.Equal(t, 1, v)
+	}
+}
+
+// A `SetValue` recording its argument in upper case
+type upperString struct {
+	s string
+}
+
+func (u *upperString) Set(s string) error { u.s = strings.ToUpper(s); return nil }
+func (u *upperString) String() string     { return u.s }
+
+func TestSetValueCount(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixDoubleHyphen = true
+	v := &upperString{}
+	fs := NewFlagSet()
+	fs.Var(v, 'n', "name", "a name")
+	f := fs.Lookup('n')
+	// Testing a value isn't an appearance of the flag
+	assert.NoError(t, f.Test("x", 0))
+	assert.Equal(t, 0, f.Count)
+	fs.Parse([]string{"-n", "--", "y"})
+	assert.Equal(t, "--", v.s)
+	assert.Equal(t, 1, f.Count)
+	assert.Equal(t, []string{"y"}, []string(*fs.OutputArgs))
+}
+
 func TestPosixEquals(u *testing.T) {
 	t := assert.TestingT(u)
 	var s string
@@ -191,6 +242,31 @@ func TestCluster2(u *testing.T) {
 	assert.Equal(t, expected, fs.OutputArgs, "GNU rule")
 }
 
+func TestClusterRequiredArg(u *testing.T) {
+	t := assert.TestingT(u)
+	var a bool
+	var s, o string
+	fs := NewFlagSet()
+	fs.Var(&a, 'a', "ant", "six legs")
+	fs.Var(&s, 's', "snake", "no legs")
+	fs.Var(&o, 'o', "owl", "two legs", WithOptionalDefault("hoot"))
+
+	// A required option-argument takes the rest of the cluster, even
+	// if it looks like flags, as with `getopt()`
+	fs.Parse([]string{"-saa"})
+	assert.Equal(t, false, a)
+	assert.Equal(t, "aa", s)
+
+	// An optional one only takes the rest if it isn't made of flags
+	fs.Reset()
+	fs.Parse([]string{"-oa"})
+	assert.Equal(t, true, a)
+	assert.Equal(t, "hoot", o)
+	fs.Reset()
+	fs.Parse([]string{"-oxy"})
+	assert.Equal(t, "xy", o)
+}
+
 func TestHyphenNumIdiom(u *testing.T) {
 	t := assert.TestingT(u)
 	var n uint
@@ -209,9 +285,369 @@ func TestHyphenNumIdiom(u *testing.T) {
 	assert.Equal(t, uint(371), n)
 }
 
-// How should `-42a5` be interpreted? <-42, a(5)>? -4(2a5)? -(0x42a5)
-// Should -NUM handle non-decimal NUM (e.g. octal, hex)?
-// Rule: if you use the -NUM idiom, you can't define numeric short flags?
+// `-42a5` is interpreted as <-42, a(5)> if `a` is a short flag,
+// otherwise `42a5` is given to the -NUM flag, which may accept it as a
+// number with a suffix (e.g. `-5K`). If you use the -NUM idiom, you
+// can't define numeric short flags.
+func TestSignedHyphenNum(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixEquals = true
+	PosixDoubleHyphen = true
+	PosixOperandStop = false
+	var lines, from int
+	var follow bool
+	var bytes int64
+	fs := NewFlagSet(WithSilentFail(), WithContinueOnFail())
+	fs.Var(&lines, 'n', "lines", "output the last NUM lines", WithIntUnits(),
+		WithRepeats(false), WithAlias(NoShort, NoLong, false))
+	fs.Var(&from, NoShort, "from", "output lines starting with the NUMth",
+		WithPlusNum(), WithRepeats(false))
+	fs.Var(&follow, 'f', "follow", "output appended data as the file grows")
+	fs.Var(&bytes, 'c', "bytes", "output the last NUM bytes",
+		WithNegativeArgs(), WithRepeats(false))
+
+	fs.Parse([]string{"-5f", "file"})
+	assert.Equal(t, 5, lines)
+	assert.Equal(t, true, follow)
+	assert.Equal(t, []string{"file"}, []string(*fs.OutputArgs))
+
+	fs.Reset()
+	fs.Parse([]string{"-5K", "+12", "file"})
+	assert.Equal(t, 5*1024, lines)
+	assert.Equal(t, 12, from)
+	assert.Equal(t, []string{"file"}, []string(*fs.OutputArgs))
+
+	fs.Reset()
+	fs.Parse([]string{"-c", "-20", "-n", "-3", "file"})
+	assert.Equal(t, int64(-20), bytes)
+	assert.Equal(t, 3, lines, "-n doesn't take negative arguments, so -3 is -NUM")
+	assert.Equal(t, []string{"file"}, []string(*fs.OutputArgs))
+
+	fs.Reset()
+	fs.Parse([]string{"-c-20"})
+	assert.Equal(t, int64(-20), bytes)
+
+	fs.Reset()
+	fs.Parse([]string{"-12fc", "7"})
+	assert.Equal(t, 12, lines)
+	assert.Equal(t, true, follow)
+	assert.Equal(t, int64(7), bytes)
+
+	// Without -NUM, +NUM or an undefined flag
+	fs = NewFlagSet(WithSilentFail(), WithContinueOnFail())
+	fs.Var(&follow, 'f', "follow", "output appended data as the file grows")
+	assert.NotPanics(t, func() { fs.Parse([]string{"-xf", "+5"}) })
+	assert.Equal(t, []string{"+5"}, []string(*fs.OutputArgs))
+
+	fs = NewFlagSet(WithSilentFail(), WithContinueOnFail())
+	fs.Var(&from, 'F', "from", "", WithPlusNum())
+	assert.Panics(t, func() { fs.Var(new(int), 'G', "go", "", WithPlusNum()) })
+	assert.Panics(t, func() { fs.Var(new(string), 's', "str", "", WithPlusNum()) })
+	assert.Panics(t, func() { fs.Var(new(bool), 'b', "bool", "", WithNegativeArgs()) })
+}
+
+func TestLongW(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixEquals = true
+	PosixDoubleHyphen = true
+	PosixOperandStop = false
+	var a bool
+	var color, format string
+	fs := NewFlagSet(WithLongW(), WithSilentFail(), WithContinueOnFail())
+	fs.Var(&a, 'a', "all", "show all")
+	fs.Var(&color, NoShort, "color", "when to use color", WithRepeats(false))
+	fs.Var(&format, NoShort, "format", "output format", WithRepeats(false))
+
+	fs.Parse([]string{"-W", "color=always", "-Wform=long", "file"})
+	assert.Equal(t, "always", color)
+	assert.Equal(t, "long", format)
+	assert.Equal(t, []string{"file"}, []string(*fs.OutputArgs))
+
+	fs.Reset()
+	fs.Parse([]string{"-aWcolor", "never", "-W", "format", "wide"})
+	assert.Equal(t, true, a)
+	assert.Equal(t, "never", color)
+	assert.Equal(t, "wide", format)
+
+	// An `=` isn't part of the long flag, which can't be a flag itself
+	fs.Reset()
+	fs.Parse([]string{"-W=color=auto", "-aW=format=tall"})
+	assert.Equal(t, "auto", color)
+	assert.Equal(t, "tall", format)
+	errs := &strings.Builder{}
+	fs = NewFlagSet(WithLongW(), WithOutputWriter(errs), WithContinueOnFail())
+	var all bool
+	fs.Var(&all, 'a', "all", "show all")
+	for _, args := range [][]string{{"-W", "--all"}, {"-W", "-a"}, {"-W", "--"}, {"-W="}, {"-W"}} {
+		errs.Reset()
+		fs.Reset()
+		fs.Parse(args)
+		assert.Contains(t, errs.String(), "'-W' requires a long flag", "%q", args)
+		assert.NotContains(t, errs.String(), "----", "%q", args)
+		assert.Equal(t, len(args) > 1 && args[1] != "--", all,
+			"%q: the flag after -W is still processed", args)
+	}
+
+	PosixRejectW = false
+	assert.Panics(t, func() {
+		NewFlagSet(WithLongW(), WithPanicOnFail()).Var(new(bool), 'W', "warn", "")
+	})
+	PosixRejectW = true
+}
+
+func TestSingleDashLongs(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixEquals = true
+	PosixDoubleHyphen = true
+	PosixOperandStop = false
+	var name, geometry, size string
+	var n, v bool
+	newFlagSet := func(precedence SingleDash, abbrev bool) *FlagSet {
+		fs := NewFlagSet(WithSingleDashLongs(precedence, abbrev),
+			WithSilentFail(), WithContinueOnFail())
+		fs.Var(&name, NoShort, "name", "match file names", WithRepeats(false))
+		fs.Var(&geometry, 'g', "geometry", "window geometry", WithRepeats(false))
+		fs.Var(&size, NoShort, "size", "match file sizes", WithRepeats(false))
+		fs.Var(&n, 'n', NoLong, "numeric")
+		fs.Var(&v, 'v', NoLong, "verbose")
+		return fs
+	}
+
+	fs := newFlagSet(LongFirst, false)
+	fs.Parse([]string{"-name", "*.go", "-geometry=80x24", "-nv", "dir"})
+	assert.Equal(t, "*.go", name)
+	assert.Equal(t, "80x24", geometry)
+	assert.Equal(t, true, n)
+	assert.Equal(t, true, v)
+	assert.Equal(t, []string{"dir"}, []string(*fs.OutputArgs))
+
+	// Without abbreviation, `-geo` is `-g` with an attached argument
+	fs.Reset()
+	fs.Parse([]string{"-geo", "--name", "x"})
+	assert.Equal(t, "eo", geometry)
+	assert.Equal(t, "x", name)
+
+	fs = newFlagSet(LongFirst, true)
+	fs.Parse([]string{"-geo", "80x24", "-na", "*.c"})
+	assert.Equal(t, "80x24", geometry)
+	assert.Equal(t, "*.c", name)
+
+	fs = newFlagSet(ClusterFirst, true)
+	fs.Parse([]string{"-geometry", "-si=+2k"})
+	assert.Equal(t, "eometry", geometry)
+	assert.Equal(t, "+2k", size)
+
+	fs = NewFlagSet(WithSingleDashLongs(LongFirst, false), WithPanicOnFail())
+	fs.Var(new(bool), 'a', NoLong, "all")
+	fs.Var(new(bool), 'l', NoLong, "long")
+	assert.Panics(t, func() { fs.Var(new(bool), NoShort, "all", "") })
+	fs.Var(new(bool), NoShort, "lx", "")
+	assert.Panics(t, func() { fs.Var(new(bool), 'x', "ax", "") })
+}
+
+func TestPlusOptions(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixEquals = true
+	PosixDoubleHyphen = true
+	PosixOperandStop = false
+	var e, x, sb, q bool
+	var level int
+	fs := NewFlagSet(WithPlusOptions(), WithSilentFail(), WithContinueOnFail())
+	fs.Var(&e, 'e', "errexit", "exit on error", PlusNegates())
+	fs.Var(&x, 'x', "xtrace", "trace commands", PlusNegates())
+	fs.Var(&sb, NoShort, "sb", "show scrollbar", PlusNegates())
+	fs.Var(&q, 'q', "quiet", "say nothing")
+	fs.Var(&level, 'l', "level", "trace level", WithRepeats(false),
+		PlusNegates(func(f *Flag, arg string, pos int) error {
+			level = -1
+			return nil
+		}))
+
+	e, x, sb = true, true, true
+	fs.Parse([]string{"+ex", "+sb", "-q", "+level", "file"})
+	assert.Equal(t, false, e)
+	assert.Equal(t, false, x)
+	assert.Equal(t, false, sb)
+	assert.Equal(t, true, q)
+	assert.Equal(t, -1, level)
+	assert.Equal(t, []string{"file"}, []string(*fs.OutputArgs))
+
+	fs.Reset()
+	fs.Parse([]string{"-x", "+x", "-l", "+e", "+xtr"})
+	assert.Equal(t, false, x, "+x isn't a repeat of -x")
+	assert.Equal(t, 0, level, "+e isn't an option-argument")
+	assert.Equal(t, []string{}, []string(*fs.OutputArgs))
+
+	fs.Reset()
+	fs.Parse([]string{"+q", "+y"})
+	assert.Equal(t, []string{}, []string(*fs.OutputArgs))
+
+	assert.Equal(t, "-e, +e, --errexit", fs.Lookup('e').FlagString())
+	assert.Equal(t, "    --sb, +sb", fs.Lookup("sb").FlagString())
+	assert.Equal(t, "-q, --quiet", fs.Lookup('q').FlagString())
+	assert.Panics(t, func() { fs.Var(new(int), 'n', "num", "", PlusNegates()) })
+
+	// Without plus options, they're operands
+	fs = NewFlagSet(WithSilentFail(), WithContinueOnFail())
+	fs.Var(&x, 'x', "xtrace", "trace commands", PlusNegates())
+	x = true
+	fs.Parse([]string{"+x"})
+	assert.Equal(t, true, x)
+	assert.Equal(t, []string{"+x"}, []string(*fs.OutputArgs))
+}
+
+func TestOperandKeys(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixEquals = true
+	PosixDoubleHyphen = true
+	PosixOperandStop = true
+	var verbose bool
+	var in, out string
+	var bs uint64
+	var count int
+	var status string
+	fs := NewFlagSet(WithSilentFail(), WithContinueOnFail())
+	fs.Var(&verbose, 'v', "verbose", "be noisy")
+	fs.NewOperandGroup("Operands")
+	fs.Var(&in, NoShort, "if", "read from FILE", WithTypeTag("FILE"))
+	fs.Var(&out, NoShort, "of", "write to FILE", WithTypeTag("FILE"))
+	fs.Var(&bs, NoShort, "bs", "read and write BYTES at a time", WithIntUnits())
+	fs.Var(&count, NoShort, "count", "copy only N input blocks")
+	fs.Var(&status, NoShort, "status", "the LEVEL of information to print",
+		WithDefault([]string{"none", "noxfer", "progress"}), WithAbbreviations())
+
+	fs.Parse([]string{"if=in.img", "bs=4K", "-v", "cou=10", "stat=prog", "x=y", "z"})
+	assert.Equal(t, "in.img", in)
+	assert.Equal(t, uint64(4096), bs)
+	assert.Equal(t, true, verbose, "key=value operands don't stop parsing")
+	assert.Equal(t, 10, count)
+	assert.Equal(t, "progress", status)
+	assert.Equal(t, "", out)
+	assert.Equal(t, []string{"x=y", "z"}, []string(*fs.OutputArgs))
+	assert.Equal(t, []string{"--verbose", "if=in.img", "bs=4096", "count=10",
+		"status=progress", "--", "x=y", "z"}, fs.Args(LongArgStyle))
+	assert.Equal(t, 10, fs.Get("count"))
+	assert.Nil(t, fs.Lookup("count"))
+
+	fs.Reset()
+	fs.Parse([]string{"status=loud", "count=x", "--if=a"})
+	assert.Equal(t, "none", status, "the first default")
+	assert.Equal(t, 0, count)
+	assert.Equal(t, "", in)
+
+	assert.Equal(t, "    if=FILE", fs.LookupKey("if").FlagString())
+	assert.Panics(t, func() { fs.Var(new(int), 'c', "conv", "") })
+
+	c := fs.Clone()
+	c.Parse([]string{"of=out.img"})
+	assert.Equal(t, "out.img", c.Get("of"))
+	assert.Equal(t, "", out)
+}
+
+// Builds a `FlagSet` for a row of `TestArgumentGrid`, where `kinds`
+// gives the kind of `-f/--flag`, `-g`, and `-h` in turn: undefined
+// (`-`), taking a prohibited (`p`), optional (`o`), or required (`r`)
+// option-argument.
+func argumentGridFlagSet(d Dialect, kinds string, values map[rune]*string) *FlagSet {
+	fs := NewFlagSet(WithDialect(d), WithSilentFail(), WithContinueOnFail())
+	for i, r := range "fgh" {
+		long := NoLong
+		if r == 'f' {
+			long = "flag"
+		}
+		value := ""
+		values[r] = &value
+		switch kinds[i] {
+		case 'p':
+			fs.Var(&boolString{&value}, r, long, "prohibited")
+		case 'o':
+			fs.Var(&value, r, long, "optional", WithOptionalDefault("dflt"))
+		case 'r':
+			fs.Var(&value, r, long, "required")
+		}
+	}
+	return fs
+}
+
+// A nullary `SetValue` recording "true" in a string
+type boolString struct {
+	s *string
+}
+
+func (b *boolString) Set(string) error { *b.s = "true"; return nil }
+func (b *boolString) String() string   { return *b.s }
+func (b *boolString) IsBoolFlag() bool { return true }
+
+// The possibilities listed at the top of parse.go
+func TestArgumentGrid(u *testing.T) {
+	t := assert.TestingT(u)
+	gnuEquals := PosixDialect
+	gnuEquals.Equals = false
+	detached := PosixDialect
+	detached.OptionalDetachedShort = true
+	detached.OptionalDetachedLong = true
+	grid := []struct {
+		args     string
+		dialect  Dialect
+		kinds    string
+		expected string
+		operands []string
+	}{
+		{"--flag", PosixDialect, "p--", "f=true", nil},
+		{"--flag", PosixDialect, "o--", "f=dflt", nil},
+		{"--flag=value", PosixDialect, "o--", "f=value", nil},
+		{"--flag=value", PosixDialect, "r--", "f=value", nil},
+		{"--flag value", PosixDialect, "r--", "f=value", nil},
+		{"--flag value", PosixDialect, "o--", "f=dflt", []string{"value"}},
+		{"--flag value", GnuDialect, "o--", "f=dflt", []string{"value"}},
+		{"--flag value", detached, "o--", "f=value", nil},
+		{"--flag operand", PosixDialect, "p--", "f=true", []string{"operand"}},
+		{"--flag operand", PosixDialect, "o--", "f=dflt", []string{"operand"}},
+		{"-f", PosixDialect, "p--", "f=true", nil},
+		{"-f", PosixDialect, "o--", "f=dflt", nil},
+		{"-f opd", PosixDialect, "p--", "f=true", []string{"opd"}},
+		{"-f opd", PosixDialect, "o--", "f=dflt", []string{"opd"}},
+		{"-f=arg", PosixDialect, "o--", "f==arg", nil},
+		{"-f=arg", PosixDialect, "r--", "f==arg", nil},
+		{"-f=arg", gnuEquals, "o--", "f=arg", nil},
+		{"-f=arg", gnuEquals, "r--", "f=arg", nil},
+		{"-f arg", PosixDialect, "r--", "f=arg", nil},
+		{"-f arg", PosixDialect, "o--", "f=dflt", []string{"arg"}},
+		{"-f arg", detached, "o--", "f=arg", nil},
+		{"-farg", PosixDialect, "o--", "f=arg", nil},
+		{"-farg", PosixDialect, "r--", "f=arg", nil},
+		{"-fgh", PosixDialect, "ppp", "f=true g=true h=true", nil},
+		{"-fgh", PosixDialect, "pp-", "f=true g=true", nil},
+		{"-fgh", PosixDialect, "pr-", "f=true g=h", nil},
+		{"-fgh", PosixDialect, "po-", "f=true g=h", nil},
+		{"-fgh", PosixDialect, "r--", "f=gh", nil},
+		{"-fgh=arg", PosixDialect, "ppr", "f=true g=true h==arg", nil},
+		{"-fgh=arg", gnuEquals, "ppr", "f=true g=true h=arg", nil},
+		{"-fgh=arg", PosixDialect, "pr-", "f=true g=h=arg", nil},
+		{"-fgh=arg", PosixDialect, "o--", "f=gh=arg", nil},
+		{"-fgh arg", PosixDialect, "ppr", "f=true g=true h=arg", nil},
+		{"-fgh arg", PosixDialect, "r--", "f=gh", []string{"arg"}},
+		{"-fgh opd", PosixDialect, "ppp", "f=true g=true h=true", []string{"opd"}},
+		{"-fgh opd", PosixDialect, "ppo", "f=true g=true h=dflt", []string{"opd"}},
+	}
+	for _, row := range grid {
+		values := map[rune]*string{}
+		fs := argumentGridFlagSet(row.dialect, row.kinds, values)
+		fs.Parse(strings.Fields(row.args))
+		expected := map[rune]string{'f': "", 'g': "", 'h': ""}
+		for _, kv := range strings.Fields(row.expected) {
+			expected[rune(kv[0])] = kv[2:]
+		}
+		for r, value := range values {
+			assert.Equal(t, expected[r], *value, "%s (%s): -%c", row.args, row.kinds, r)
+		}
+		operands := []string(*fs.OutputArgs)
+		if row.operands == nil {
+			row.operands = []string{}
+		}
+		assert.Equal(t, row.operands, operands, "%s (%s): operands", row.args, row.kinds)
+	}
+}
 
 func TestRepeats(u *testing.T) {
 	t := assert.TestingT(u)
@@ -232,6 +668,57 @@ func TestRepeats(u *testing.T) {
 	assert.Equal(t, []string{"ant", "sheep", "pig", "horse", "cow"}, sa)
 }
 
+func TestCounterOperands(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixOperandStop = false
+	var v int
+	fs := NewFlagSet()
+	fs.Var(&v, 'v', "verbose", "be noisy", AsCounter())
+
+	// A counter never takes a detached option-argument, so the
+	// following word is an operand
+	for _, c := range []struct {
+		args  []string
+		count int
+	}{
+		{[]string{"-v", "file"}, 1},
+		{[]string{"--verbose", "file"}, 1},
+		{[]string{"-vv", "file"}, 2},
+		{[]string{"-v", "file", "-v"}, 2},
+	} {
+		fs.Reset()
+		v = 0
+		fs.Parse(c.args)
+		assert.Equal(t, c.count, v, "%q", c.args)
+		assert.Equal(t, []string{"file"}, []string(*fs.OutputArgs), "%q", c.args)
+	}
+}
+
+func TestDetachedArgFailure(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixOperandStop = false
+	r := &testReporter{}
+	var n, o int
+	fs := NewFlagSet(WithReporter(r), WithContinueOnFail(),
+		WithDialect(Dialect{Equals: true, OptionalDetachedShort: true}))
+	fs.Var(&n, 'n', "number", "a number")
+	fs.Var(&o, 'o', "other", "another number", WithOptionalDefault(7))
+
+	// A required option-argument that can't be set is reported, once,
+	// and left as an operand
+	fs.Parse([]string{"-n", "foo", "bar"})
+	assert.Len(t, r.messages, 1)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, []string{"foo", "bar"}, []string(*fs.OutputArgs))
+
+	// An optional one isn't taken, and the flag gets its default
+	fs.Reset()
+	fs.Parse([]string{"-o", "foo"})
+	assert.Len(t, r.messages, 1)
+	assert.Equal(t, 7, o)
+	assert.Equal(t, []string{"foo"}, []string(*fs.OutputArgs))
+}
+
 // Callback Test Function
 func cbtf(f *Flag, arg string, pos int) error {
 	return f.SetOnly("foo", pos)
@@ -247,3 +734,262 @@ func TestCallback(u *testing.T) {
 	fs.Parse(args)
 	assert.Equal(t, "foo", s)
 }
+
+func TestParseString(u *testing.T) {
+	t := assert.TestingT(u)
+	var i bool
+	var color string
+	sa := []string{}
+	fs := NewFlagSet(WithSilentFail(), WithContinueOnFail())
+	fs.Var(&i, 'i', "ignore-case", "ignore case")
+	fs.Var(&color, NoShort, "color", "when to use color")
+	fs.Var(&sa, 'e', "regexp", "a pattern")
+
+	PosixOperandStop = false
+	err := fs.ParseString(`-i --color=always 'a b' "c\"d" -e "$PAT" -e $PAT`,
+		shlex.WithEnv(map[string]string{"PAT": "x y"}))
+	assert.Nil(t, err)
+	assert.Equal(t, true, i)
+	assert.Equal(t, "always", color)
+	assert.Equal(t, []string{"x y", "x"}, sa)
+	expected := &deque.Deque[string]{}
+	expected.Init("a b", `c"d`, "y")
+	assert.Equal(t, expected, fs.OutputArgs)
+
+	fs.Reset()
+	err = fs.ParseString(`-e 'unterminated`)
+	var serr *shlex.SyntaxError
+	assert.ErrorAs(t, err, &serr)
+	assert.Equal(t, 3, serr.Offset)
+
+	// The error is returned, rather than exited on, with the default
+	// `OnFail`
+	codes := []int{}
+	fs = NewFlagSet(WithExitFunc(func(code int) { codes = append(codes, code) }))
+	fs.Var(&sa, 'e', "regexp", "a pattern")
+	err = fs.ParseString(`-e "unterminated`)
+	assert.ErrorAs(t, err, &serr)
+	assert.Empty(t, codes)
+}
+
+func TestNArgs(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixEquals = true
+	PosixDoubleHyphen = true
+	PosixOperandStop = false
+	var point []float64
+	var geometry [2]uint
+	var names []string
+	var verbose bool
+	fs := NewFlagSet(WithSilentFail(), WithContinueOnFail())
+	fs.Var(&point, 'p', "point", "add a point", WithNArgs(2), WithTypeTag("X Y"))
+	fs.Var(&geometry, 'g', "geometry", "window size", WithNArgs(2))
+	fs.Var(&names, 'n', NoLong, "some names", WithNArgsRange(1, 3), WithTypeTag("NAME"))
+	fs.Var(&verbose, 'v', NoLong, "be noisy")
+	assert.Panics(t, func() { fs.Var(&verbose, 'b', NoLong, "", WithNArgs(2)) })
+	assert.Panics(t, func() { fs.Var(new([1]int), 'a', NoLong, "", WithNArgs(2)) })
+	assert.Panics(t, func() { fs.Var(new(int), 'i', NoLong, "", WithNArgs(2)) })
+	assert.Panics(t, func() { fs.Var(&names, 'm', NoLong, "", WithNArgsRange(2, 1)) })
+
+	assert.Equal(t, "-p X Y, --point X Y", fs.Lookup("point").FlagString())
+	assert.Equal(t, "-g NUM NUM, --geometry NUM NUM", fs.Lookup("geometry").FlagString())
+	assert.Equal(t, "-n NAME [NAME] [NAME]", fs.Lookup('n').FlagString())
+
+	fs.Parse([]string{"--point", "1", "2", "op1", "-p3", "4", "--geometry=640", "480",
+		"-vn", "a", "b", "-n", "c", "d", "e", "op2", "-nf", "--", "op3"})
+	assert.Equal(t, []float64{1, 2, 3, 4}, point)
+	assert.Equal(t, [2]uint{640, 480}, geometry)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e", "f"}, names)
+	assert.True(t, verbose)
+	assert.Equal(t, []string{"op1", "op2", "op3"}, []string(*fs.OutputArgs))
+	assert.Equal(t, 2, fs.Lookup("point").Count)
+	assert.Equal(t, []string{"--point", "1", "2", "--point", "3", "4",
+		"--geometry", "640", "480", "-n", "a", "b", "c", "-n", "d", "e", "f", "-v",
+		"--", "op1", "op2", "op3"}, fs.Args(LongArgStyle))
+
+	// Too few, non-numeric, and repeated arrays leave the values alone
+	fs.Parse([]string{"--point", "5", "-v"})
+	fs.Parse([]string{"--point", "5", "x"})
+	fs.Parse([]string{"-g", "1", "2"})
+	assert.Equal(t, []float64{1, 2, 3, 4}, point)
+	assert.Equal(t, [2]uint{640, 480}, geometry)
+	err := fs.Lookup("point").SetArgs([]string{"5"}, 1)
+	assert.ErrorContains(t, err, "expected 2 arguments, got 1")
+	assert.Error(t, fs.Lookup('v').SetArgs([]string{"5"}, 1))
+
+	fs.Reset()
+	assert.Empty(t, point)
+	assert.Equal(t, [2]uint{0, 0}, geometry)
+	fs.Parse([]string{"-g", "1", "2"})
+	assert.Equal(t, [2]uint{1, 2}, geometry)
+	fs.Parse([]string{"-g", "3,4"})
+	assert.Equal(t, [2]uint{1, 2}, geometry)
+}
+
+func FuzzParseSingleArg(f *testing.F) {
+	for _, arg := range []string{"", "-", "--", "-a", "--all", "-abc", "-a=x",
+		"--all=x", "-=", "--=", "-12", "+5", "+x", "x=y", "-\xff", "--\xff=\xfe"} {
+		f.Add(arg)
+	}
+	f.Fuzz(func(t *testing.T, arg string) {
+		flags, param, argType := parseSingleArg(arg)
+		// None of the sanity checks may fail for any argument
+		argType.IsFlag()
+		argType.IsDoubleHyphen()
+		argType.IsShortFlag()
+		argType.IsLongFlag()
+		argType.IsCluster()
+		argType.IsNumber()
+		argType.HasParam()
+		argType.IsNonFlag()
+		if argType.IsFlag() && !strings.Contains(arg, flags) {
+			t.Errorf("flags %q not in %q", flags, arg)
+		}
+		if !strings.HasSuffix(arg, param) {
+			t.Errorf("param %q not at end of %q", param, arg)
+		}
+	})
+}
+
+// Function `fuzzFlagSet()` builds a `FlagSet` from a fuzzer's spec, in
+// which the first byte picks the dialect and each subsequent byte
+// defines a flag, having its short and long names chosen by the byte
+// modulo 26 and its kind by the remainder.
+func fuzzFlagSet(spec []byte, out *strings.Builder) *FlagSet {
+	dialects := []Dialect{PosixDialect, GnuDialect,
+		{Equals: true, OptionalDetachedShort: true, OptionalDetachedLong: true}}
+	d := PosixDialect
+	if len(spec) > 0 {
+		d = dialects[int(spec[0])%len(dialects)]
+		spec = spec[1:]
+	}
+	// The next byte, if any, selects the `FlagSet` options
+	var features byte
+	if len(spec) > 0 {
+		features = spec[0]
+		spec = spec[1:]
+	}
+	opts := []FlagSetOption{WithDialect(d), WithContinueOnFail(), WithOutputWriter(out)}
+	if features&1 != 0 {
+		opts = append(opts, WithLongW())
+	}
+	if precedence := SingleDash(features / 2 % 4); precedence != ClusterOnly && precedence <= ClusterFirst {
+		opts = append(opts, WithSingleDashLongs(precedence, features&8 != 0))
+	}
+	if features&16 != 0 {
+		opts = append(opts, WithPlusOptions())
+	}
+	fs := NewFlagSet(opts...)
+	hyphenNum := false
+	for _, b := range spec {
+		short := rune('a' + b%26)
+		if fs.LookupShort(short) != nil {
+			continue
+		}
+		long := string(short) + "-flag"
+		switch b / 26 % 10 {
+		case 0:
+			fs.Var(new(bool), short, long, "bool")
+		case 1:
+			fs.Var(new(int), short, long, "counter", AsCounter())
+		case 2:
+			fs.Var(new(string), short, long, "string", WithRepeats(false))
+		case 3:
+			fs.Var(new(string), short, long, "optional", WithRepeats(false),
+				WithOptionalDefault("opt"))
+		case 4:
+			fs.Var(new(int), short, long, "int", WithRepeats(false))
+		case 5:
+			fs.Var(new([]string), short, long, "list")
+		case 6:
+			if hyphenNum {
+				continue
+			}
+			hyphenNum = true
+			fs.Var(new(int), NoShort, NoLong, "lines")
+		case 7:
+			fs.Var(new(bool), short, long, "negatable", PlusNegates())
+		case 8:
+			fs.Var(new([]int), short, long, "one or two", WithNArgsRange(1, 2))
+		case 9:
+			fs.Var(new(map[string]string), short, long, "pairs")
+		}
+	}
+	if features&32 != 0 {
+		fs.NewOperandGroup("operands")
+		fs.Var(new(string), NoShort, "if", "input", WithRepeats(false))
+		fs.Var(new(int), NoShort, "bs", "block size", WithRepeats(false))
+	}
+	return fs
+}
+
+// Function `isSubsequence()` reports whether all of the items in `sub`
+// appear in `seq` in the same order.
+func isSubsequence(sub, seq []string) bool {
+	i := 0
+	for _, s := range seq {
+		if i < len(sub) && sub[i] == s {
+			i++
+		}
+	}
+	return i == len(sub)
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add([]byte{0, 0, 27, 54, 81, 108, 135, 156},
+		"-abbcx\n-d\n--e-flag=3\nop\n-12\n--f-flag=x,y\n--\n-a")
+	f.Add([]byte{1, 0, 27, 54, 81, 108, 135, 156},
+		"op\n-dval\n--b-flag\n-c-\n-f\nx,y\n--\n--")
+	f.Add([]byte{2, 81, 27}, "-d\n--b-flag\nop\n-b\n-")
+	f.Add([]byte{0, 1 | 1<<1 | 16 | 32, 0, 27, 188, 215, 242, 254},
+		"-a\nif=x\n-W\nb-flag\n+g\n-h\n1\n2\n-i\nk=v,w\n-b-flag\nbs=4")
+	f.Add([]byte{1, 2<<1 | 8 | 16, 0, 27, 188, 215, 242},
+		"-gb\n+g\n-h3\n--i-flag=x=y\nop\n-b")
+	f.Fuzz(func(t *testing.T, spec []byte, argv string) {
+		args := strings.Split(argv, "\n")
+		out := &strings.Builder{}
+		fs := fuzzFlagSet(spec, out)
+		fs.Parse(args)
+		if strings.Contains(out.String(), "ERROR") {
+			return
+		}
+		operands := []string(*fs.OutputArgs)
+		if !isSubsequence(operands, args) {
+			t.Fatalf("%q: operands %q not taken from arguments", args, operands)
+		}
+		// A word after a flag that takes no argument is an operand
+		words := []string{}
+		for i := 1; i < len(args); i++ {
+			flags, _, argType := parseSingleArg(args[i-1])
+			if argType.IsCluster() || !argType.IsFlag() || argType.HasParam() {
+				continue
+			}
+			prev := fs.Lookup(flags)
+			nextFlags, _, nextType := parseSingleArg(args[i])
+			key, _, _ := strings.Cut(args[i], "=")
+			if prev != nil && (prev.IsBool() || prev.IsCounter()) &&
+				!nextType.IsFlag() && !nextType.IsDoubleHyphen() &&
+				!fs.isPlusOption(nextFlags, nextType) && fs.LookupKey(key) == nil {
+				words = append(words, args[i])
+			}
+		}
+		if !isSubsequence(words, operands) {
+			t.Fatalf("%q: operands %q don't include %q", args, operands, words)
+		}
+
+		// The reconstructed command line reparses to the same thing
+		canonical := fs.Args(LongArgStyle)
+		out2 := &strings.Builder{}
+		fs2 := fuzzFlagSet(spec, out2)
+		fs2.Parse(canonical)
+		if strings.Contains(out2.String(), "ERROR") {
+			t.Fatalf("%q: canonical %q fails: %s", args, canonical, out2)
+		}
+		if again := fs2.Args(LongArgStyle); !assert.ObjectsAreEqual(canonical, again) {
+			t.Fatalf("%q: canonical %q reparses as %q", args, canonical, again)
+		}
+		if again := []string(*fs2.OutputArgs); !assert.ObjectsAreEqual(operands, again) {
+			t.Fatalf("%q: operands %q reparse as %q", args, operands, again)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+// Package `pflagcompat` moves flag definitions between `fflag` and
+// spf13's `pflag` package, in the same way as
+// `fflag.ImportGoFlagSet()` and `fflag.ExportGoFlagSet()` do for the
+// standard library's `flag` package. It is separate so that programs
+// that don't use `pflag` don't have to build it.
+package pflagcompat
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/EmmetCaulfield/fflag"
+	"github.com/EmmetCaulfield/fflag/pkg/types"
+	"github.com/spf13/pflag"
+)
+
+// Function `Import()` registers every flag defined in a
+// `pflag.FlagSet` with an `fflag.FlagSet`, in its current
+// `FlagGroup`. The name becomes the long option and the shorthand, if
+// any, the short option, except that a single-rune name becomes a
+// short option. The value is wrapped in an `fflag.GoFlagValue`, the
+// placeholder name in the usage (see `pflag.UnquoteUsage()`) becomes
+// the type tag, a `NoOptDefVal` other than a boolean's becomes an
+// optional default, and deprecated and hidden flags remain so. The
+// given options are applied to every imported flag.
+//
+// An error is returned, and no more flags are imported, if a name
+// isn't valid as an option or is already in use.
+func Import(fs *fflag.FlagSet, pfs *pflag.FlagSet, opts ...fflag.FlagOption) error {
+	var err error
+	pfs.VisitAll(func(pf *pflag.Flag) {
+		if err != nil {
+			return
+		}
+		short := fflag.NoShort
+		if utf8.RuneCountInString(pf.Name) > 1 && pf.Shorthand != "" {
+			short, _ = utf8.DecodeRuneInString(pf.Shorthand)
+		}
+		tag, usage := pflag.UnquoteUsage(pf)
+		options := []fflag.FlagOption{fflag.WithTypeTag(strings.ToUpper(tag))}
+		if pf.NoOptDefVal != "" && !(&fflag.GoFlagValue{Value: pf.Value}).IsBoolFlag() {
+			options = append(options, fflag.WithOptionalDefault(pf.NoOptDefVal))
+		}
+		if pf.Deprecated != "" {
+			options = append(options, fflag.Deprecated())
+		}
+		if pf.Hidden {
+			options = append(options, hidden())
+		}
+		options = append(options, opts...)
+		err = fs.ImportValue(pf.Name, short, pf.Value, usage, options...)
+	})
+	return err
+}
+
+func hidden() fflag.FlagOption {
+	return func(f *fflag.Flag) error {
+		f.Type.SetHiddenBit()
+		return nil
+	}
+}
+
+// Function `Export()` defines every flag in an `fflag.FlagSet` on a
+// `pflag.FlagSet`. The long option becomes the name and the short
+// option, if it is a single byte, the shorthand. A flag having only a
+// short option is named by it. Setting the `pflag` value sets the
+// `Flag` with `Flag.Set()` (see `Flag.GoValue()`). Aliases and the
+// -NUM idiom are not exported.
+//
+// An error is returned if a name or shorthand is already defined in
+// the `pflag.FlagSet`, since `pflag` would panic.
+func Export(fs *fflag.FlagSet, pfs *pflag.FlagSet) error {
+	for _, g := range fs.Groups {
+		for _, f := range g.FlagList {
+			if f.IsAlias() || f.IsHyphenNum() {
+				continue
+			}
+			name, shorthand := f.Long, ""
+			if f.Short != fflag.NoShort {
+				if name == fflag.NoLong {
+					name = string(f.Short)
+				}
+				if utf8.RuneLen(f.Short) == 1 {
+					shorthand = string(f.Short)
+				}
+			}
+			if pfs.Lookup(name) != nil {
+				return fmt.Errorf("flag '%s' already defined", name)
+			}
+			if shorthand != "" && pfs.ShorthandLookup(shorthand) != nil {
+				return fmt.Errorf("shorthand '%s' already defined", shorthand)
+			}
+			pf := pfs.VarPF(f.GoValue().(pflag.Value), name, shorthand, f.Usage)
+			switch {
+			case f.IsBool():
+				pf.NoOptDefVal = "true"
+			case f.IsCounter():
+				// The argument is ignored by counters
+				pf.NoOptDefVal = "+1"
+			case f.Type.TstDefOptionalBit():
+				pf.NoOptDefVal = types.StrConv(f.GetDefault())
+			}
+			if f.Type.TstObsoleteBit() {
+				pf.Deprecated = "it is obsolete"
+			}
+			pf.Hidden = f.IsHidden()
+		}
+	}
+	return nil
+}
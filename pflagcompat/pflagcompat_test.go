@@ -0,0 +1,98 @@
+package pflagcompat
+
+import (
+	"io"
+	"testing"
+
+	"github.com/EmmetCaulfield/fflag"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImport(u *testing.T) {
+	t := assert.TestingT(u)
+	fflag.PosixEquals = true
+	fflag.PosixDoubleHyphen = true
+	fflag.PosixOperandStop = true
+
+	pfs := pflag.NewFlagSet("lib", pflag.ContinueOnError)
+	verbose := pfs.BoolP("verbose", "v", false, "be noisy")
+	level := pfs.CountP("level", "l", "how noisy")
+	names := pfs.StringSliceP("name", "n", []string{}, "some `NAMES`")
+	color := pfs.String("color", "never", "when to use color")
+	pfs.Lookup("color").NoOptDefVal = "auto"
+	x := pfs.Int("x", 0, "an x")
+	old := pfs.Int("old", 0, "old thing")
+	pfs.MarkDeprecated("old", "use new")
+	secret := pfs.Int("secret", 0, "a secret")
+	pfs.MarkHidden("secret")
+
+	fs := fflag.NewFlagSet(fflag.WithSilentFail(), fflag.WithContinueOnFail())
+	assert.NoError(t, Import(fs, pfs))
+	assert.Equal(t, "-v, --verbose", fs.Lookup("verbose").FlagString())
+	assert.Equal(t, "-n NAMES, --name=NAMES", fs.Lookup("name").FlagString())
+	assert.Equal(t, "    --color[=STRING]", fs.Lookup("color").FlagString())
+	assert.Equal(t, "-x INT", fs.Lookup('x').FlagString())
+	assert.True(t, fs.Lookup("old").Type.TstObsoleteBit())
+	assert.True(t, fs.Lookup("secret").IsHidden())
+
+	fs.Parse([]string{"-vll", "-n", "a,b", "--name=c", "--color", "-x", "4",
+		"--old=1", "--level", "--secret", "2", "operand"})
+	assert.Equal(t, true, *verbose)
+	assert.Equal(t, 3, *level)
+	assert.Equal(t, []string{"a", "b", "c"}, *names)
+	assert.Equal(t, "auto", *color)
+	assert.Equal(t, 4, *x)
+	assert.Equal(t, 1, *old)
+	assert.Equal(t, 2, *secret)
+	assert.Equal(t, []string{"operand"}, []string(*fs.OutputArgs))
+
+	fs.Parse([]string{"--color=always"})
+	assert.Equal(t, "always", *color)
+
+	// Names already in use
+	assert.Error(t, Import(fs, pfs))
+}
+
+func TestExport(u *testing.T) {
+	t := assert.TestingT(u)
+	var verbose bool
+	var level uint
+	var snake string
+	var n int
+	color := "never"
+	fs := fflag.NewFlagSet(fflag.WithSilentFail(), fflag.WithContinueOnFail())
+	fs.Var(&verbose, 'v', "verbose", "be noisy", fflag.WithAlias(fflag.NoShort, "loud", false))
+	fs.Var(&level, 'l', fflag.NoLong, "how noisy", fflag.AsCounter())
+	fs.Var(&snake, 's', "snake", "a snake",
+		fflag.WithDefault([]string{"adder", "boa", "python"}))
+	fs.Var(&n, fflag.NoShort, "number", "a number", fflag.Deprecated())
+	fs.Var(&color, fflag.NoShort, "color", "when to use color",
+		fflag.WithOptionalDefault([]string{"auto", "never", "always"}))
+
+	pfs := pflag.NewFlagSet("prog", pflag.ContinueOnError)
+	pfs.SetOutput(io.Discard)
+	assert.NoError(t, Export(fs, pfs))
+	assert.Nil(t, pfs.Lookup("loud"))
+	assert.Equal(t, "v", pfs.Lookup("verbose").Shorthand)
+	assert.Equal(t, "l", pfs.Lookup("l").Shorthand)
+	assert.Equal(t, "adder", pfs.Lookup("snake").DefValue)
+	assert.Equal(t, "enum", pfs.Lookup("snake").Value.Type())
+	assert.NotEmpty(t, pfs.Lookup("number").Deprecated)
+
+	err := pfs.Parse([]string{"-vll", "--snake", "python", "--number=3",
+		"--color", "operand"})
+	assert.NoError(t, err)
+	assert.Equal(t, true, verbose)
+	assert.Equal(t, uint(2), level)
+	assert.Equal(t, "python", snake)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, "auto", color)
+	assert.Equal(t, []string{"operand"}, pfs.Args())
+
+	assert.Error(t, pfs.Parse([]string{"-s", "cobra"}))
+	assert.Equal(t, "python", snake)
+
+	// Names already defined
+	assert.Error(t, Export(fs, pfs))
+}
@@ -0,0 +1,263 @@
+// Package `shlex` splits a string into words following the quoting
+// rules of the POSIX shell, so that a whole command line held as a
+// single string (e.g. typed into an interactive console or stored in
+// a configuration file) can be given to a `FlagSet` as if it had come
+// from `os.Args`.
+//
+// Words are separated by unquoted blanks (space, tab, or newline). A
+// backslash preserves the literal value of the following character,
+// except that a backslash-newline pair is removed entirely (line
+// continuation). Single quotes preserve the literal value of every
+// character up to the closing single quote. Double quotes preserve
+// the literal value of every character up to the closing double quote
+// except `$` and backslash, which only escapes `$`, “`”, `"`,
+// backslash, and newline. A `#` at the start of a word begins a
+// comment that runs to the end of the line.
+//
+// Parameter expansion of `$NAME` and `${NAME}` is performed only if an
+// environment is supplied with `WithEnv()`, otherwise `$` is an
+// ordinary character. As in the shell, unset names expand to nothing
+// and the result of an unquoted expansion is split into words at
+// blanks, while the result of a quoted expansion is not.
+//
+// Other shell syntax (command substitution, arithmetic, globbing,
+// redirection, pipelines, etc.) is not supported and the characters
+// involved have no special meaning.
+package shlex
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnterminatedQuote = errors.New("unterminated quoted string")
+	ErrTrailingBackslash = errors.New("backslash at end of input")
+	ErrBadSubstitution   = errors.New("bad substitution")
+)
+
+// A `SyntaxError` reports a malformed string and the offset (in
+// characters, i.e. runes, from zero) where the problem begins.
+type SyntaxError struct {
+	Offset int
+	Err    error
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%v at offset %d", e.Err, e.Offset)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// A `Lexer` holds the state of the word-splitting of a single string.
+type Lexer struct {
+	env    map[string]string
+	input  []rune
+	pos    int
+	words  []string
+	word   strings.Builder
+	inWord bool
+}
+
+// Functional option type for `Split()` options.
+type Option = func(l *Lexer)
+
+// Option `WithEnv()` enables parameter expansion of `$NAME` and
+// `${NAME}` using the values in the given map.
+func WithEnv(env map[string]string) Option {
+	return func(l *Lexer) {
+		l.env = env
+	}
+}
+
+// Function `Split()` splits a string into words using POSIX shell
+// quoting rules.
+func Split(s string, opts ...Option) ([]string, error) {
+	l := &Lexer{
+		input: []rune(s),
+		words: []string{},
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	err := l.split()
+	if err != nil {
+		return nil, err
+	}
+	return l.words, nil
+}
+
+func isBlank(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n'
+}
+
+func isNameStart(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func isName(r rune) bool {
+	return isNameStart(r) || (r >= '0' && r <= '9')
+}
+
+func (l *Lexer) errorAt(pos int, err error) error {
+	return &SyntaxError{Offset: pos, Err: err}
+}
+
+func (l *Lexer) add(r rune) {
+	l.word.WriteRune(r)
+	l.inWord = true
+}
+
+func (l *Lexer) end() {
+	if l.inWord {
+		l.words = append(l.words, l.word.String())
+	}
+	l.word.Reset()
+	l.inWord = false
+}
+
+func (l *Lexer) split() error {
+	for l.pos < len(l.input) {
+		r := l.input[l.pos]
+		switch {
+		case isBlank(r):
+			l.end()
+			l.pos++
+		case r == '#' && !l.inWord:
+			for l.pos < len(l.input) && l.input[l.pos] != '\n' {
+				l.pos++
+			}
+		case r == '\\':
+			if l.pos+1 >= len(l.input) {
+				return l.errorAt(l.pos, ErrTrailingBackslash)
+			}
+			if l.input[l.pos+1] != '\n' {
+				l.add(l.input[l.pos+1])
+			}
+			l.pos += 2
+		case r == '\'':
+			err := l.singleQuoted()
+			if err != nil {
+				return err
+			}
+		case r == '"':
+			err := l.doubleQuoted()
+			if err != nil {
+				return err
+			}
+		case r == '$' && l.env != nil:
+			value, err := l.expand()
+			if err != nil {
+				return err
+			}
+			// Field splitting of an unquoted expansion
+			for _, v := range value {
+				if isBlank(v) {
+					l.end()
+				} else {
+					l.add(v)
+				}
+			}
+		default:
+			l.add(r)
+			l.pos++
+		}
+	}
+	l.end()
+	return nil
+}
+
+func (l *Lexer) singleQuoted() error {
+	start := l.pos
+	// Even an empty quoted string is a word
+	l.inWord = true
+	for l.pos++; l.pos < len(l.input); l.pos++ {
+		if l.input[l.pos] == '\'' {
+			l.pos++
+			return nil
+		}
+		l.word.WriteRune(l.input[l.pos])
+	}
+	return l.errorAt(start, ErrUnterminatedQuote)
+}
+
+func (l *Lexer) doubleQuoted() error {
+	start := l.pos
+	l.inWord = true
+	l.pos++
+	for l.pos < len(l.input) {
+		r := l.input[l.pos]
+		switch {
+		case r == '"':
+			l.pos++
+			return nil
+		case r == '\\' && l.pos+1 < len(l.input):
+			next := l.input[l.pos+1]
+			switch next {
+			case '$', '`', '"', '\\':
+				l.word.WriteRune(next)
+			case '\n':
+				// Line continuation
+			default:
+				l.word.WriteRune(r)
+				l.word.WriteRune(next)
+			}
+			l.pos += 2
+		case r == '$' && l.env != nil:
+			value, err := l.expand()
+			if err != nil {
+				return err
+			}
+			l.word.WriteString(value)
+		default:
+			l.word.WriteRune(r)
+			l.pos++
+		}
+	}
+	return l.errorAt(start, ErrUnterminatedQuote)
+}
+
+// Function `expand()` expands the parameter whose `$` is at the
+// current position, leaving the position after the parameter. A `$`
+// that isn't followed by a name or a brace is literal.
+func (l *Lexer) expand() (string, error) {
+	start := l.pos
+	l.pos++
+	if l.pos < len(l.input) && l.input[l.pos] == '{' {
+		end := l.pos + 1
+		for end < len(l.input) && l.input[end] != '}' {
+			end++
+		}
+		if end >= len(l.input) {
+			return "", l.errorAt(start, ErrBadSubstitution)
+		}
+		name := string(l.input[l.pos+1 : end])
+		if !validName(name) {
+			return "", l.errorAt(start, ErrBadSubstitution)
+		}
+		l.pos = end + 1
+		return l.env[name], nil
+	}
+	if l.pos >= len(l.input) || !isNameStart(l.input[l.pos]) {
+		return "$", nil
+	}
+	end := l.pos
+	for end < len(l.input) && isName(l.input[end]) {
+		end++
+	}
+	name := string(l.input[l.pos:end])
+	l.pos = end
+	return l.env[name], nil
+}
+
+func validName(name string) bool {
+	for i, r := range name {
+		if !isName(r) || (i == 0 && !isNameStart(r)) {
+			return false
+		}
+	}
+	return name != ""
+}
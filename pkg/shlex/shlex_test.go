@@ -0,0 +1,77 @@
+package shlex
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	env := map[string]string{
+		"HOME":  "/home/user",
+		"FILES": " a.txt  b.txt ",
+		"EMPTY": "",
+	}
+	testCases := []struct {
+		in  string
+		env map[string]string
+		out []string
+	}{
+		{"", nil, []string{}},
+		{"   \t\n ", nil, []string{}},
+		{"-i --color=always", nil, []string{"-i", "--color=always"}},
+		{`-i --color=always 'a b' "c\"d"`, nil, []string{"-i", "--color=always", "a b", `c"d`}},
+		{`'' ""`, nil, []string{"", ""}},
+		{`a\ b c\\d \'`, nil, []string{"a b", `c\d`, "'"}},
+		{"a\\\nb", nil, []string{"ab"}},
+		{`'a\b'`, nil, []string{`a\b`}},
+		{`"a\b" "\$\` + "`" + `"`, nil, []string{`a\b`, "$`"}},
+		{`x'y'"z"`, nil, []string{"xyz"}},
+		{"grep -e foo # comment\n-i", nil, []string{"grep", "-e", "foo", "-i"}},
+		{"a#b", nil, []string{"a#b"}},
+		{"ünï cödé", nil, []string{"ünï", "cödé"}},
+		{"$HOME", nil, []string{"$HOME"}},
+		{"$HOME/x ${HOME}y", env, []string{"/home/user/x", "/home/usery"}},
+		{"$NOPE a $EMPTY", env, []string{"a"}},
+		{`"$EMPTY"`, env, []string{""}},
+		{"ls $FILES", env, []string{"ls", "a.txt", "b.txt"}},
+		{`ls "$FILES"`, env, []string{"ls", " a.txt  b.txt "}},
+		{`'$HOME' \$HOME $ $1`, env, []string{"$HOME", "$HOME", "$", "$1"}},
+	}
+	for i, test := range testCases {
+		out, err := Split(test.in, WithEnv(test.env))
+		if err != nil {
+			t.Errorf("case %d: unexpected error splitting %q: %v", i, test.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(out, test.out) {
+			t.Errorf("case %d: splitting %q, expected %q, got %q", i, test.in, test.out, out)
+		}
+	}
+}
+
+func TestSplitErrors(t *testing.T) {
+	env := map[string]string{}
+	testCases := []struct {
+		in     string
+		err    error
+		offset int
+	}{
+		{`abc 'def`, ErrUnterminatedQuote, 4},
+		{`ä "b\"`, ErrUnterminatedQuote, 2},
+		{`abc\`, ErrTrailingBackslash, 3},
+		{`a ${HOME`, ErrBadSubstitution, 2},
+		{`a "${1x}"`, ErrBadSubstitution, 3},
+	}
+	for i, test := range testCases {
+		_, err := Split(test.in, WithEnv(env))
+		if !errors.Is(err, test.err) {
+			t.Errorf("case %d: expected %v splitting %q, got %v", i, test.err, test.in, err)
+			continue
+		}
+		var serr *SyntaxError
+		if !errors.As(err, &serr) || serr.Offset != test.offset {
+			t.Errorf("case %d: expected error at offset %d, got %v", i, test.offset, err)
+		}
+	}
+}
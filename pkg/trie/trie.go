@@ -2,6 +2,8 @@ package trie
 
 import(
 	"fmt"
+	"sort"
+	"strings"
 	"unicode/utf8"
 )
 
@@ -16,11 +18,15 @@ func firstRune(s string) (rune, string) {
 	if len(s) == 0 {
 		return utf8.RuneError, ""
 	}
-	for _, char := range s {
-		return char, s[utf8.RuneLen(char):]
-	}
-	// Impossible
-	panic("unreachable code")
+	char, size := utf8.DecodeRuneInString(s)
+	return char, s[size:]
+}
+
+// Reports whether the first rune, as returned by `firstRune()`, is a
+// real one rather than an indication of an empty string or invalid
+// UTF-8, so that U+FFFD itself can be a key
+func validFirst(r rune, key string) bool {
+	return r != utf8.RuneError || strings.HasPrefix(key, string(utf8.RuneError))
 }
 
 func (t *TrieNode[T]) Get(key string) (*T, error) {
@@ -34,11 +40,10 @@ func (t *TrieNode[T]) Get(key string) (*T, error) {
 		return t.Item, nil
 	}
 	r, tail := firstRune(key)
-	if r == utf8.RuneError {
+	if !validFirst(r, key) {
 		// We either got a bona-fide utf8 rune error or passed the
-		// empty string to firstRune(), neither of which we're
-		// prepared to handle
-		panic("unexpected string error")
+		// empty string to firstRune()
+		return nil, fmt.Errorf("invalid UTF-8 in key")
 	}
 	if node, ok := t.Nodes[r]; ok {
 		return node.Get(tail)
@@ -49,7 +54,7 @@ func (t *TrieNode[T]) Get(key string) (*T, error) {
 	
 func (t *TrieNode[T]) moveDown(item *T) {
 	tR, tTail := firstRune(t.Tail)
-	if tR == utf8.RuneError {
+	if !validFirst(tR, t.Tail) {
 		panic("unexpected string error")
 	}
 	t.Nodes[tR] = &TrieNode[T]{
@@ -62,6 +67,9 @@ func (t *TrieNode[T]) moveDown(item *T) {
 }
 
 func (t *TrieNode[T]) Add(key string, item *T) error {
+	if !utf8.ValidString(key) {
+		return fmt.Errorf("invalid UTF-8 in key")
+	}
 	if len(key) == 0 {
 		// We've exhausted the key
 		if t.Item == nil {
@@ -87,9 +95,6 @@ func (t *TrieNode[T]) Add(key string, item *T) error {
 	}
 
 	r, tail := firstRune(key)
-	if r == utf8.RuneError {
-		panic("unexpected string error")
-	}
 	if node, ok := t.Nodes[r]; ok {
 		return node.Add(tail, item)
 	}
@@ -102,6 +107,30 @@ func (t *TrieNode[T]) Add(key string, item *T) error {
 	return nil
 }
 
+// Returns, in lexicographic order, every key in the trie beginning
+// with the given prefix (e.g. for completion)
+func (t *TrieNode[T]) Keys(prefix string) []string {
+	keys := []string{}
+	t.collect("", &keys)
+	matches := []string{}
+	for _, key := range keys {
+		if strings.HasPrefix(key, prefix) {
+			matches = append(matches, key)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+func (t *TrieNode[T]) collect(path string, keys *[]string) {
+	if t.Item != nil {
+		*keys = append(*keys, path+t.Tail)
+	}
+	for r, node := range t.Nodes {
+		node.collect(path+string(r), keys)
+	}
+}
+
 func NewTrie[T any]() *TrieNode[T] {
 	return &TrieNode[T]{
 		Item: nil,
@@ -4,6 +4,7 @@ import (
 	//	"fmt"
 	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func TestTrieBasics(t *testing.T) {
@@ -81,3 +82,61 @@ func TestTrieBasics(t *testing.T) {
 		}
 	}
 }
+
+func TestTrieKeys(t *testing.T) {
+	trie := NewTrie[string]()
+	contents := []string{"foo", "bar", "bazaar", "baz", "fop", "quux", "exclude", "exclude-dir"}
+	for _, s := range contents {
+		v := s
+		trie.Add(s, &v)
+	}
+	testCases := []struct {
+		prefix string
+		keys   []string
+	}{
+		{"", []string{"bar", "baz", "bazaar", "exclude", "exclude-dir", "foo", "fop", "quux"}},
+		{"ba", []string{"bar", "baz", "bazaar"}},
+		{"baz", []string{"baz", "bazaar"}},
+		{"exclude", []string{"exclude", "exclude-dir"}},
+		{"q", []string{"quux"}},
+		{"x", []string{}},
+	}
+	for _, test := range testCases {
+		keys := trie.Keys(test.prefix)
+		if strings.Join(keys, " ") != strings.Join(test.keys, " ") {
+			t.Errorf("wrong keys for prefix '%s': expected %v, got %v", test.prefix, test.keys, keys)
+		}
+	}
+}
+
+func FuzzTrie(f *testing.F) {
+	f.Add("foo\nbar\nbazaar\nbaz\nfop\nquux", "ba")
+	f.Add("exclude\nexclude-dir\n\n\xff", "excl")
+	f.Add("�\n�x\nx�", "\xfe")
+	f.Fuzz(func(t *testing.T, keys string, probe string) {
+		trie := NewTrie[string]()
+		added := map[string]bool{}
+		for _, key := range strings.Split(keys, "\n") {
+			v := key
+			err := trie.Add(key, &v)
+			if err == nil {
+				if added[key] {
+					t.Errorf("duplicate key '%s' added", key)
+				}
+				added[key] = true
+			} else if utf8.ValidString(key) && !added[key] {
+				t.Errorf("failed to add '%s': %v", key, err)
+			}
+		}
+		for key := range added {
+			n, err := trie.Get(key)
+			if err != nil || n == nil || *n != key {
+				t.Errorf("failed to retrieve '%s': %v", key, err)
+			}
+		}
+		if n, _ := trie.Get(probe); n != nil && !strings.HasPrefix(*n, probe) {
+			t.Errorf("retrieved '%s' for '%s'", *n, probe)
+		}
+		trie.Keys(probe)
+	})
+}
@@ -0,0 +1,96 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Function `arrayType()` identifies a fixed-size array of a supported
+// scalar type, or a pointer to one, in the same way as `mapType()`.
+func arrayType(ix interface{}) (TypeId, bool) {
+	t := reflect.TypeOf(ix)
+	if t == nil {
+		return 0, false
+	}
+	var typeId TypeId
+	if t.Kind() == reflect.Pointer {
+		typeId.SetPointerBit()
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Array || t.Len() == 0 {
+		return 0, false
+	}
+	elem := Type(reflect.Zero(t.Elem()).Interface())
+	if elem&(SliceT|PointerT|SetterT|OtherT|MapT|ArrayT) != 0 {
+		return 0, false
+	}
+	return typeId | ArrayT | elem, true
+}
+
+func arrayValue(ix interface{}) (reflect.Value, bool) {
+	if !IsArray(ix) {
+		return reflect.Value{}, false
+	}
+	rv := reflect.ValueOf(ix)
+	if rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	return rv, true
+}
+
+func arrayStr(rv reflect.Value, param *StrConvParams, opts []StrConvOption) string {
+	strs := make([]string, rv.Len())
+	for i := range strs {
+		strs[i] = StrConv(rv.Index(i).Interface(), opts...)
+	}
+	return strings.Join(strs, param.sep)
+}
+
+// Function `arrayFromStr()` sets every element of an array, so the
+// number of items must match its length.
+func arrayFromStr(ix interface{}, str string, doSet bool, param *StrConvParams, opts []StrConvOption) error {
+	rv, _ := arrayValue(ix)
+	items := strings.Split(str, param.sep)
+	if len(items) != rv.Len() {
+		return fmt.Errorf("expected %d items, got %d in '%s'", rv.Len(), len(items), str)
+	}
+	c := reflect.New(rv.Type()).Elem()
+	for i, item := range items {
+		err := FromStr(c.Index(i).Addr().Interface(), strings.TrimSpace(item), true, opts...)
+		if err != nil {
+			return err
+		}
+	}
+	if doSet {
+		rv.Set(c)
+	}
+	return nil
+}
+
+// Function `SetItem()` sets the element at index `i` of the slice or
+// array behind a pointer from a string, appending to a slice if `i`
+// is beyond its end.
+func SetItem(ix interface{}, i int, str string, doSet bool, opts ...StrConvOption) error {
+	typeId := Type(ix)
+	if !typeId.TstPointerBit() || (!typeId.TstSliceBit() && !typeId.TstArrayBit()) || typeId.TstOtherBit() || typeId.TstSetterBit() {
+		return fmt.Errorf("interface (%v) does not represent a pointer to a slice or array (%T)", ix, ix)
+	}
+	rv := reflect.ValueOf(ix).Elem()
+	if i < 0 || (rv.Kind() == reflect.Array && i >= rv.Len()) {
+		return fmt.Errorf("index %d out of range for %T", i, ix)
+	}
+	elem := reflect.New(rv.Type().Elem())
+	if err := FromStr(elem.Interface(), str, true, opts...); err != nil {
+		return err
+	}
+	if !doSet {
+		return nil
+	}
+	if i < rv.Len() {
+		rv.Index(i).Set(elem.Elem())
+	} else {
+		rv.Set(reflect.Append(rv, elem.Elem()))
+	}
+	return nil
+}
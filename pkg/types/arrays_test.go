@@ -0,0 +1,40 @@
+package types
+
+import "testing"
+
+func TestArrays(t *testing.T) {
+	var a [3]int
+	if !IsArray(&a) || !IsInt(a) || IsSlice(&a) || SliceLen(&a) != 3 {
+		t.Error("array type predicates failed")
+	}
+	if IsArray([0]int{}) || IsArray(&[2][]int{}) {
+		t.Error("unsupported array recognized")
+	}
+	if err := FromStr(&a, "1, 2,3", true); err != nil || a != [3]int{1, 2, 3} {
+		t.Errorf("got %v (%v), expected [1 2 3]", a, err)
+	}
+	if FromStr(&a, "4,5", true) == nil || FromStr(&a, "4,5,x", true) == nil || a != [3]int{1, 2, 3} {
+		t.Errorf("expected errors leaving the array alone, got %v", a)
+	}
+	if StrConv(a, WithSep(" ")) != "1 2 3" || ItemAt(&a, 2) != 3 || ItemAt(&a, 3) != nil {
+		t.Errorf("got '%s', expected '1 2 3'", StrConv(a, WithSep(" ")))
+	}
+
+	if err := SetItem(&a, 1, "7", true); err != nil || a[1] != 7 {
+		t.Errorf("got %v (%v), expected a[1] == 7", a, err)
+	}
+	if SetItem(&a, 3, "7", true) == nil || SetItem(a, 0, "7", true) == nil {
+		t.Error("expected an error setting a bad index or a non-pointer")
+	}
+	s := []string{"a"}
+	if err := SetItem(&s, 0, "b", true); err != nil || len(s) != 1 || s[0] != "b" {
+		t.Errorf("got %v (%v), expected [b]", s, err)
+	}
+	if err := SetItem(&s, 5, "c,d", true); err != nil || len(s) != 2 || s[1] != "c,d" {
+		t.Errorf("got %v (%v), expected [b c,d]", s, err)
+	}
+	f := []float32{}
+	if SetItem(&f, 0, "x", true) == nil || SetItem(&f, 0, "1", false) != nil || len(f) != 0 {
+		t.Errorf("unexpected SetItem() result: %v", f)
+	}
+}
@@ -0,0 +1,108 @@
+package types
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// A `ByteSize` is a number of bytes. On the command line, it is an
+// integer with an optional multiplicative suffix, as accepted by GNU
+// coreutils (e.g. `head -c 10K`): `b` for 512, or one of `K` (or
+// `k`), `M`, `G`, `T`, `P`, `E`, `Z`, `Y`, `R`, or `Q` for a power of
+// 1024, optionally followed by `iB`, or followed by `B` for a power
+// of 1000 instead. So `1K` and `1KiB` are both 1024, but `1KB` is
+// 1000.
+type ByteSize uint64
+
+const sizeUnits = "KMGTPEZYRQ"
+
+// Function `ParseByteSize()` parses a size with an optional suffix.
+func ParseByteSize(s string) (ByteSize, error) {
+	digits := strings.TrimRightFunc(s, func(r rune) bool {
+		return r < '0' || r > '9'
+	})
+	suffix := s[len(digits):]
+	if digits == "" || strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return 0, fmt.Errorf("invalid size '%s'", s)
+	}
+	n, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size '%s': %w", s, err)
+	}
+
+	n, err = mulSuffix(s, n, suffix)
+	return ByteSize(n), err
+}
+
+// Function `mulSuffix()` multiplies `n` by the multiplier that a size
+// suffix (e.g. `K`, `MiB`, `GB`) stands for.
+func mulSuffix(s string, n uint64, suffix string) (uint64, error) {
+	var base, exp uint64 = 1024, 0
+	switch {
+	case suffix == "":
+		return n, nil
+	case suffix == "b":
+		return mulSize(s, n, 512)
+	case suffix[0] == 'k':
+		exp = 1
+	default:
+		exp = uint64(strings.IndexByte(sizeUnits, suffix[0]) + 1)
+	}
+	switch suffix[1:] {
+	case "", "iB":
+	case "B":
+		base = 1000
+	default:
+		exp = 0
+	}
+	if exp == 0 {
+		return 0, fmt.Errorf("invalid suffix '%s' in '%s'", suffix, s)
+	}
+	var err error
+	for ; exp > 0; exp-- {
+		n, err = mulSize(s, n, base)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+func mulSize(s string, n, m uint64) (uint64, error) {
+	hi, lo := bits.Mul64(n, m)
+	if hi != 0 {
+		return 0, fmt.Errorf("'%s' is too large", s)
+	}
+	return lo, nil
+}
+
+// Function `String()` renders a size using the largest suffix that
+// represents it exactly, preferring powers of 1024, so that the result
+// can be parsed back to the same size.
+func (b ByteSize) String() string {
+	n := uint64(b)
+	if n == 0 {
+		return "0"
+	}
+	unit := ""
+	for i := 0; i < len(sizeUnits); i++ {
+		switch {
+		case n%1024 == 0:
+			n /= 1024
+			unit = sizeUnits[i : i+1]
+			continue
+		case unit == "" && n%1000 == 0:
+			// Decimal only if the whole thing is decimal
+			m := n
+			j := 0
+			for ; j < len(sizeUnits) && m%1000 == 0; j++ {
+				m /= 1000
+			}
+			return strconv.FormatUint(m, 10) + sizeUnits[j-1:j] + "B"
+		}
+		break
+	}
+	return strconv.FormatUint(n, 10) + unit
+}
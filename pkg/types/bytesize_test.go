@@ -0,0 +1,103 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseByteSize(t *testing.T) {
+	testCases := []struct {
+		s    string
+		n    ByteSize
+		back string
+	}{
+		{"0", 0, "0"},
+		{"100", 100, "100"},
+		{"2b", 1024, "1K"},
+		{"10K", 10240, "10K"},
+		{"10k", 10240, "10K"},
+		{"10KiB", 10240, "10K"},
+		{"10KB", 10000, "10KB"},
+		{"3M", 3 << 20, "3M"},
+		{"5GB", 5000000000, "5GB"},
+		{"1536K", 1536 << 10, "1536K"},
+		{"15E", 15 << 60, "15E"},
+	}
+	for _, tc := range testCases {
+		n, err := ParseByteSize(tc.s)
+		if err != nil {
+			t.Errorf("unexpected error parsing '%s': %v", tc.s, err)
+			continue
+		}
+		if n != tc.n {
+			t.Errorf("parsing '%s': got %d, expected %d", tc.s, n, tc.n)
+		}
+		if n.String() != tc.back {
+			t.Errorf("rendering %d: got '%s', expected '%s'", n, n.String(), tc.back)
+		}
+	}
+	for _, s := range []string{"", "K", "-1", "1.5K", "1X", "1KiBB", "1Kb", "15EiB1", "16E", "1Z"} {
+		if _, err := ParseByteSize(s); err == nil {
+			t.Errorf("expected an error parsing '%s'", s)
+		}
+	}
+}
+
+func TestDurationTimeSize(t *testing.T) {
+	var d time.Duration
+	if err := FromStr(&d, "1h30m", true); err != nil || d != 90*time.Minute {
+		t.Errorf("got %v (%v), expected 1h30m", d, err)
+	}
+	if StrConv(d) != "1h30m0s" {
+		t.Errorf("got '%s', expected '1h30m0s'", StrConv(d))
+	}
+	if FromStr(&d, "90", true) == nil {
+		t.Error("expected an error for a duration without a unit")
+	}
+
+	var tm time.Time
+	if err := FromStr(&tm, "2024-02-29", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tm.Year() != 2024 || tm.Month() != 2 || tm.Day() != 29 {
+		t.Errorf("got %v, expected 2024-02-29", tm)
+	}
+	if err := FromStr(&tm, "29/02/2024", true, WithLayouts("02/01/2006")); err != nil || tm.Day() != 29 {
+		t.Errorf("got %v (%v), expected 29 Feb 2024", tm, err)
+	}
+	if StrConv(tm, WithLayouts("02/01/2006")) != "29/02/2024" {
+		t.Errorf("got '%s', expected '29/02/2024'", StrConv(tm, WithLayouts("02/01/2006")))
+	}
+	if FromStr(&tm, "yesterday", true) == nil {
+		t.Error("expected an error for an unparseable time")
+	}
+
+	var ds []time.Duration
+	if err := FromStr(&ds, "1s,2m", true); err != nil || len(ds) != 2 || ds[1] != 2*time.Minute {
+		t.Errorf("got %v (%v), expected [1s 2m0s]", ds, err)
+	}
+	var sz ByteSize
+	if err := FromStr(&sz, "4K", true); err != nil || sz != 4096 {
+		t.Errorf("got %v (%v), expected 4096", sz, err)
+	}
+	if !IsDuration(&ds) || !IsTime(tm) || !IsSize(&sz) || IsSize(&d) {
+		t.Error("type predicates failed")
+	}
+
+	v, err := CoerceScalar(sz, "2M")
+	if err != nil || v != ByteSize(2<<20) {
+		t.Errorf("got %v (%v), expected 2M", v, err)
+	}
+	v, err = CoerceScalar(d, "1s")
+	if err != nil || v != time.Second {
+		t.Errorf("got %v (%v), expected 1s", v, err)
+	}
+	if _, err = CoerceScalar(sz, -1); err == nil {
+		t.Error("expected an error coercing a negative size")
+	}
+	a, _ := time.Parse(time.RFC3339, "2024-01-01T12:00:00Z")
+	b := a.In(time.FixedZone("X", 3600))
+	if a == b || !Equal(a, b) || Equal(a, a.Add(1)) || !Equal(1, 1) {
+		t.Error("Equal() failed")
+	}
+}
@@ -2,7 +2,11 @@ package types
 
 import(
     "fmt"
+    "net"
+    "net/netip"
+    "net/url"
     "strconv"
+    "time"
 
     "golang.org/x/exp/constraints"
 )
@@ -69,10 +73,82 @@ func RangeTest[T Number, R Number](t T, r R) error {
 	return fmt.Errorf("value %v<%T> is not representable in %T", t, t, r)
 }
 
+
+// Function `coerceParsed()` coerces a value of type `T` or a string,
+// parsed with the given function, to `T`.
+func coerceParsed[T any](val interface{}, parse func(string) (T, error)) (interface{}, bool, error) {
+	switch v := val.(type) {
+	case T:
+		return v, true, nil
+	case string:
+		x, err := parse(v)
+		return x, true, err
+	}
+	var zero T
+	return nil, true, fmt.Errorf("cannot coerce %T to %T", val, zero)
+}
+
+// Function `coerceSpecial()` coerces to and from the scalar types that
+// aren't basic types (durations, times, byte sizes, network addresses,
+// and URLs), for which
+// only conversion from the same type or a string, or to a string,
+// makes sense. The boolean result is `false` if neither argument is
+// such a type.
+func coerceSpecial(ref interface{}, val interface{}) (interface{}, bool, error) {
+	switch ref.(type) {
+	case time.Duration:
+		return coerceParsed(val, time.ParseDuration)
+	case time.Time:
+		return coerceParsed(val, func(s string) (time.Time, error) {
+			return ParseTime(s)
+		})
+	case ByteSize:
+		switch v := val.(type) {
+		case ByteSize:
+			return v, true, nil
+		case uint64:
+			return ByteSize(v), true, nil
+		case uint:
+			return ByteSize(v), true, nil
+		case int:
+			if v < 0 {
+				return nil, true, fmt.Errorf("negative size %d", v)
+			}
+			return ByteSize(v), true, nil
+		case string:
+			b, err := ParseByteSize(v)
+			return b, true, err
+		}
+		return nil, true, fmt.Errorf("cannot coerce %T to types.ByteSize", val)
+	case net.IP:
+		return coerceParsed(val, (&StrConvParams{}).parseIP)
+	case netip.Addr:
+		return coerceParsed(val, netip.ParseAddr)
+	case netip.Prefix:
+		return coerceParsed(val, netip.ParsePrefix)
+	case netip.AddrPort:
+		return coerceParsed(val, netip.ParseAddrPort)
+	case HostPort:
+		return coerceParsed(val, (&StrConvParams{}).parseHostPort)
+	case url.URL:
+		return coerceParsed(val, (&StrConvParams{}).parseURL)
+	case string:
+		switch val.(type) {
+		case time.Duration, time.Time, ByteSize, net.IP, netip.Addr,
+			netip.Prefix, netip.AddrPort, HostPort, url.URL:
+			return StrConv(val), true, nil
+		}
+	}
+	return nil, false, nil
+}
+
 func CoerceScalar(ref interface{}, val interface{}) (interface{}, error) {
     if ref == nil || val == nil {
         return nil, fmt.Errorf("nil argument given")
     }
+    if v, ok, err := coerceSpecial(ref, val); ok {
+        return v, err
+    }
     switch ref.(type) {
     case bool:
         switch v := val.(type) {
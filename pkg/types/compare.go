@@ -0,0 +1,129 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+)
+
+// Function `Compare()` returns -1, 0, or +1 as `a` is less than, equal
+// to, or greater than `b`, which may be any mix of integer and
+// floating-point values, including named types such as
+// `time.Duration` and `ByteSize`, or pointers to them.
+func Compare(a, b interface{}) (int, error) {
+	ra, err := numValue(a)
+	if err != nil {
+		return 0, err
+	}
+	rb, err := numValue(b)
+	if err != nil {
+		return 0, err
+	}
+	if isFloatKind(ra.Kind()) || isFloatKind(rb.Kind()) {
+		fa, fb := toFloat(ra), toFloat(rb)
+		switch {
+		case fa < fb:
+			return -1, nil
+		case fa > fb:
+			return 1, nil
+		case fa == fb:
+			return 0, nil
+		}
+		return 0, fmt.Errorf("cannot compare %v with %v", a, b)
+	}
+	aNeg, aMag := signMag(ra)
+	bNeg, bMag := signMag(rb)
+	if aNeg != bNeg {
+		if aNeg {
+			return -1, nil
+		}
+		return 1, nil
+	}
+	c := 0
+	switch {
+	case aMag < bMag:
+		c = -1
+	case aMag > bMag:
+		c = 1
+	}
+	if aNeg {
+		c = -c
+	}
+	return c, nil
+}
+
+// Function `OnStep()` reports whether `v` is `from` plus a whole
+// number of steps of size `step`, which may be any mix of numbers, as
+// for `Compare()`. If any is floating-point, a small relative error is
+// allowed.
+func OnStep(v, from, step interface{}) (bool, error) {
+	rv, err := numValue(v)
+	if err != nil {
+		return false, err
+	}
+	rf, err := numValue(from)
+	if err != nil {
+		return false, err
+	}
+	rs, err := numValue(step)
+	if err != nil {
+		return false, err
+	}
+	if c, _ := Compare(step, 0); c == 0 {
+		return false, fmt.Errorf("step cannot be zero")
+	}
+	if isFloatKind(rv.Kind()) || isFloatKind(rf.Kind()) || isFloatKind(rs.Kind()) {
+		q := (toFloat(rv) - toFloat(rf)) / toFloat(rs)
+		return math.Abs(q-math.Round(q)) <= 1e-9*math.Max(1, math.Abs(q)), nil
+	}
+	d := new(big.Int).Sub(bigInt(rv), bigInt(rf))
+	return d.Rem(d, bigInt(rs)).Sign() == 0, nil
+}
+
+func bigInt(rv reflect.Value) *big.Int {
+	neg, mag := signMag(rv)
+	b := new(big.Int).SetUint64(mag)
+	if neg {
+		b.Neg(b)
+	}
+	return b
+}
+
+func numValue(ix interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(ix)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.CanInt() || rv.CanUint() || rv.CanFloat() {
+		return rv, nil
+	}
+	return rv, fmt.Errorf("%v (%T) is not a number", ix, ix)
+}
+
+func isFloatKind(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+func toFloat(rv reflect.Value) float64 {
+	switch {
+	case rv.CanInt():
+		return float64(rv.Int())
+	case rv.CanUint():
+		return float64(rv.Uint())
+	}
+	return rv.Float()
+}
+
+// Returns the sign and magnitude of an integer, which covers the full
+// range of both signed and unsigned 64-bit integers
+func signMag(rv reflect.Value) (bool, uint64) {
+	if rv.CanUint() {
+		return false, rv.Uint()
+	}
+	i := rv.Int()
+	if i < 0 {
+		return true, uint64(-i)
+	}
+	return false, uint64(i)
+}
@@ -0,0 +1,74 @@
+package types
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCompare(t *testing.T) {
+	n := 5
+	testCases := []struct {
+		a, b interface{}
+		c    int
+	}{
+		{1, 2, -1},
+		{uint8(200), int8(-1), 1},
+		{int64(math.MinInt64), uint64(math.MaxUint64), -1},
+		{int64(-3), int8(-2), -1},
+		{uint64(math.MaxUint64), uint64(math.MaxUint64), 0},
+		{1.5, 1, 1},
+		{-0.5, uint(0), -1},
+		{&n, 5, 0},
+		{time.Second, int64(1e9), 0},
+		{ByteSize(1024), 1000, 1},
+	}
+	for _, tc := range testCases {
+		c, err := Compare(tc.a, tc.b)
+		if err != nil {
+			t.Errorf("unexpected error comparing %v and %v: %v", tc.a, tc.b, err)
+		} else if c != tc.c {
+			t.Errorf("compare %v (%T) with %v (%T): expected %d, got %d", tc.a, tc.a, tc.b, tc.b, tc.c, c)
+		}
+	}
+	for _, bad := range []interface{}{"1", nil, []int{1}} {
+		if _, err := Compare(bad, 1); err == nil {
+			t.Errorf("unexpected success comparing %v", bad)
+		}
+	}
+	if _, err := Compare(math.NaN(), 1); err == nil {
+		t.Error("unexpected success comparing NaN")
+	}
+}
+
+func TestOnStep(t *testing.T) {
+	testCases := []struct {
+		v, from, step interface{}
+		ok            bool
+	}{
+		{10, 0, 5, true},
+		{12, 0, 5, false},
+		{uint16(1026), 1024, 2, true},
+		{int8(-3), 1, 2, true},
+		{int8(-4), 1, 2, false},
+		{uint64(math.MaxUint64), int64(math.MinInt64) + 1, 2, true},
+		{0.3, 0, 0.1, true},
+		{0.35, 0, 0.1, false},
+		{90 * time.Second, time.Minute, 30 * time.Second, true},
+		{ByteSize(3072), 0, ByteSize(1024), true},
+	}
+	for _, tc := range testCases {
+		ok, err := OnStep(tc.v, tc.from, tc.step)
+		if err != nil {
+			t.Errorf("unexpected error for %v from %v by %v: %v", tc.v, tc.from, tc.step, err)
+		} else if ok != tc.ok {
+			t.Errorf("%v from %v by %v: expected %v, got %v", tc.v, tc.from, tc.step, tc.ok, ok)
+		}
+	}
+	if _, err := OnStep(1, 0, 0); err == nil {
+		t.Error("unexpected success with a zero step")
+	}
+	if _, err := OnStep("1", 0, 1); err == nil {
+		t.Error("unexpected success with a non-number")
+	}
+}
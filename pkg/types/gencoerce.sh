@@ -144,6 +144,9 @@ func CoerceScalar(ref interface{}, val interface{}) (interface{}, error) {
     if ref == nil || val == nil {
         return nil, fmt.Errorf("nil argument given")
     }
+    if v, ok, err := coerceSpecial(ref, val); ok {
+        return v, err
+    }
     switch ref.(type) {
 EOF
 }
@@ -0,0 +1,176 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Integers may be given as Go/C literals: with a `0x`, `0o`, or `0b`
+// prefix for hexadecimal, octal, or binary, and with underscores
+// between digits (e.g. `0x1ff`, `1_000_000`). If `octalZero` is true,
+// a leading zero also means octal, as in `chmod` (e.g. `0755`),
+// otherwise it is ignored. Integers are rendered in the base last seen
+// (see `WithSeenBase()`) or given with `WithBase()`, with the prefix
+// for that base.
+func WithLiterals(octalZero bool) StrConvOption {
+	return func(p *StrConvParams) {
+		p.literals = true
+		p.octalZero = octalZero
+	}
+}
+
+// Integers may have a multiplicative suffix, as for a `ByteSize`
+// (e.g. `10K` for 10240 or `10KB` for 10000), except after a
+// hexadecimal literal, where it would be ambiguous.
+func WithUnits() StrConvOption {
+	return func(p *StrConvParams) {
+		p.units = true
+	}
+}
+
+// The base of integer literals is recorded in `base` whenever a value
+// is set (see `WithLiterals()`), so that the value can be rendered in
+// the same base.
+func WithSeenBase(base *int) StrConvOption {
+	return func(p *StrConvParams) {
+		p.seenBase = base
+	}
+}
+
+// Function `literal()` splits an integer into its sign, the digits,
+// with any prefix and underscores, and the base given by the prefix.
+func (p *StrConvParams) literal(s string) (bool, string, int) {
+	neg := false
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	if !p.literals {
+		return neg, s, p.base
+	}
+	if len(s) > 1 && s[0] == '0' {
+		switch s[1] {
+		case 'x', 'X':
+			return neg, s, 16
+		case 'o', 'O':
+			return neg, s, 8
+		case 'b', 'B':
+			return neg, s, 2
+		}
+		if !p.octalZero {
+			// Strip leading zeros so that strconv doesn't treat it as
+			// octal
+			trimmed := strings.TrimLeft(s, "0_")
+			if trimmed == "" || trimmed[0] < '0' || trimmed[0] > '9' {
+				trimmed = "0" + trimmed
+			}
+			return neg, trimmed, 10
+		}
+		return neg, s, 8
+	}
+	return neg, s, 10
+}
+
+// Function `magnitude()` parses an integer, without its sign, as a
+// 64-bit magnitude, returning it and the base it was given in.
+func (p *StrConvParams) magnitude(s string) (bool, uint64, int, error) {
+	neg, digits, base := p.literal(s)
+	suffix := ""
+	if p.units && base != 16 {
+		i := strings.LastIndexFunc(digits, func(r rune) bool {
+			return (r >= '0' && r <= '9') || r == '_'
+		})
+		digits, suffix = digits[:i+1], digits[i+1:]
+	}
+	parseBase := base
+	if p.literals {
+		parseBase = 0
+	}
+	u, err := strconv.ParseUint(digits, parseBase, 64)
+	if err != nil {
+		return neg, 0, base, fmt.Errorf("invalid integer '%s'", s)
+	}
+	u, err = mulSuffix(s, u, suffix)
+	return neg, u, base, err
+}
+
+func (p *StrConvParams) noteBase(base int) {
+	if p.seenBase != nil {
+		*p.seenBase = base
+	}
+}
+
+// Function `parseUint()` is `strconv.ParseUint()` in the base given
+// with `WithBase()` unless literals or units are allowed.
+func (p *StrConvParams) parseUint(s string, bitSize int) (uint64, error) {
+	if !p.literals && !p.units {
+		return strconv.ParseUint(s, p.base, bitSize)
+	}
+	neg, u, base, err := p.magnitude(s)
+	if err != nil {
+		return 0, err
+	}
+	if neg && u != 0 {
+		return 0, fmt.Errorf("negative value '%s' for unsigned integer", s)
+	}
+	if bitSize < 64 && u >= 1<<bitSize {
+		return 0, fmt.Errorf("'%s' is out of range for %d-bit unsigned integer", s, bitSize)
+	}
+	p.noteBase(base)
+	return u, nil
+}
+
+// Function `parseInt()` is `strconv.ParseInt()` in the base given with
+// `WithBase()` unless literals or units are allowed.
+func (p *StrConvParams) parseInt(s string, bitSize int) (int64, error) {
+	if !p.literals && !p.units {
+		return strconv.ParseInt(s, p.base, bitSize)
+	}
+	neg, u, base, err := p.magnitude(s)
+	if err != nil {
+		return 0, err
+	}
+	limit := uint64(1) << (bitSize - 1)
+	if u > limit || (!neg && u == limit) {
+		return 0, fmt.Errorf("'%s' is out of range for %d-bit integer", s, bitSize)
+	}
+	p.noteBase(base)
+	if neg {
+		return -int64(u), nil
+	}
+	return int64(u), nil
+}
+
+// Function `prefix()` returns the literal prefix for the base.
+func (p *StrConvParams) prefix() string {
+	if !p.literals {
+		return ""
+	}
+	switch p.base {
+	case 16:
+		return "0x"
+	case 8:
+		if p.octalZero {
+			return "0"
+		}
+		return "0o"
+	case 2:
+		return "0b"
+	}
+	return ""
+}
+
+func (p *StrConvParams) formatUint(u uint64) string {
+	if u == 0 {
+		return "0"
+	}
+	return p.prefix() + strconv.FormatUint(u, p.base)
+}
+
+func (p *StrConvParams) formatInt(i int64) string {
+	if i < 0 {
+		return "-" + p.formatUint(uint64(-i))
+	}
+	return p.formatUint(uint64(i))
+}
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestIntLiterals(t *testing.T) {
+	testCases := []struct {
+		s         string
+		octalZero bool
+		units     bool
+		n         int64
+		base      int
+		back      string
+	}{
+		{"0x1ff", false, false, 0x1ff, 16, "0x1ff"},
+		{"0X1FF", false, false, 0x1ff, 16, "0x1ff"},
+		{"0755", true, false, 0o755, 8, "0755"},
+		{"0755", false, false, 755, 10, "755"},
+		{"0_755", false, false, 755, 10, "755"},
+		{"0o755", false, false, 0o755, 8, "0o755"},
+		{"0b1010", false, false, 10, 2, "0b1010"},
+		{"1_000_000", false, false, 1000000, 10, "1000000"},
+		{"-0x10", false, false, -16, 16, "-0x10"},
+		{"+42", false, false, 42, 10, "42"},
+		{"0", true, false, 0, 10, "0"},
+		{"00", true, false, 0, 8, "0"},
+		{"10K", false, true, 10240, 10, "10240"},
+		{"2KB", false, true, 2000, 10, "2000"},
+		{"0x10", false, true, 16, 16, "0x10"},
+		{"-3M", false, true, -3 << 20, 10, "-3145728"},
+	}
+	for _, tc := range testCases {
+		var n int64
+		base := 0
+		opts := []StrConvOption{WithLiterals(tc.octalZero), WithSeenBase(&base)}
+		if tc.units {
+			opts = append(opts, WithUnits())
+		}
+		if err := FromStr(&n, tc.s, true, opts...); err != nil {
+			t.Errorf("unexpected error parsing '%s': %v", tc.s, err)
+			continue
+		}
+		if n != tc.n || base != tc.base {
+			t.Errorf("'%s': expected %d in base %d, got %d in base %d", tc.s, tc.n, tc.base, n, base)
+		}
+		back := StrConv(n, WithLiterals(tc.octalZero), WithBase(base))
+		if back != tc.back {
+			t.Errorf("'%s': expected '%s' back, got '%s'", tc.s, tc.back, back)
+		}
+	}
+}
+
+func TestIntLiteralErrors(t *testing.T) {
+	var u8 uint8
+	var i8 int8
+	var u uint
+	lit := WithLiterals(false)
+	for _, tc := range []struct {
+		ix interface{}
+		s  string
+	}{
+		{&u8, "0x100"}, {&u8, "256"}, {&i8, "128"}, {&i8, "-0x81"}, {&u, "-1"},
+		{&u, "0x"}, {&u, "1__0"}, {&u, "12Q"}, {&u, "10X"}, {&u, "0x10K"},
+	} {
+		if err := FromStr(tc.ix, tc.s, true, lit, WithUnits()); err == nil {
+			t.Errorf("unexpected success parsing '%s' as %T", tc.s, tc.ix)
+		}
+	}
+	if err := FromStr(&i8, "-0x80", true, lit); err != nil || i8 != -128 {
+		t.Errorf("expected -128, got %d (%v)", i8, err)
+	}
+	// Without literals, prefixes are still rejected
+	if err := FromStr(&u, "0x10", true); err == nil {
+		t.Error("unexpected success parsing '0x10' without literals")
+	}
+	// The base isn't recorded unless the value is set
+	base := 0
+	if err := FromStr(&u, "0x10", false, lit, WithSeenBase(&base)); err != nil || base != 0 {
+		t.Errorf("expected base 0, got %d (%v)", base, err)
+	}
+	var us []uint
+	if err := FromStr(&us, "0x10, 0b11", true, lit, WithSeenBase(&base)); err != nil || base != 2 {
+		t.Errorf("expected base 2, got %d (%v)", base, err)
+	}
+	if s := StrConv(us, lit, WithBase(2), WithSep(",")); s != "0b10000,0b11" {
+		t.Errorf("expected '0b10000,0b11', got '%s'", s)
+	}
+}
@@ -0,0 +1,188 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// A `DupKeyPolicy` says what happens when a key is given to a map
+// that already has it (see `WithDupKeys()`).
+type DupKeyPolicy uint8
+
+const (
+	DupKeysOverwrite DupKeyPolicy = iota // the last value prevails
+	DupKeysKeepFirst                     // the first value prevails
+	DupKeysError                         // a repeated key is an error
+)
+
+// Maps take `key=value` pairs with the given separator between the
+// key and value
+func WithPairSep(sep string) StrConvOption {
+	return func(p *StrConvParams) {
+		p.pairSep = sep
+	}
+}
+
+func WithDupKeys(policy DupKeyPolicy) StrConvOption {
+	return func(p *StrConvParams) {
+		p.dupKeys = policy
+	}
+}
+
+// Keys recorded in `seen` are the duplicates for `WithDupKeys()` and
+// keys that are set are recorded in it. Without it, keys already in
+// the map are the duplicates.
+func WithSeenKeys(seen map[string]struct{}) StrConvOption {
+	return func(p *StrConvParams) {
+		p.seenKeys = seen
+	}
+}
+
+// Each key is passed to `check` before it is set and an error
+// returned by it is returned
+func WithKeyCheck(check func(key string) error) StrConvOption {
+	return func(p *StrConvParams) {
+		p.keyCheck = check
+	}
+}
+
+// Function `mapType()` identifies a map with string keys and values
+// of a supported scalar type, or a pointer to one, since the possible
+// combinations are too many to enumerate in `Type()`.
+func mapType(ix interface{}) (TypeId, bool) {
+	t := reflect.TypeOf(ix)
+	if t == nil {
+		return 0, false
+	}
+	var typeId TypeId
+	if t.Kind() == reflect.Pointer {
+		typeId.SetPointerBit()
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String {
+		return 0, false
+	}
+	elem := Type(reflect.Zero(t.Elem()).Interface())
+	if elem&(SliceT|PointerT|SetterT|OtherT|MapT) != 0 {
+		return 0, false
+	}
+	return typeId | MapT | elem, true
+}
+
+func mapValue(ix interface{}) (reflect.Value, bool) {
+	if !IsMap(ix) {
+		return reflect.Value{}, false
+	}
+	rv := reflect.ValueOf(ix)
+	if rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	return rv, true
+}
+
+// Returns the number of entries in the underlying map or -1 if not
+// applicable
+func MapLen(ix interface{}) int {
+	rv, ok := mapValue(ix)
+	if !ok {
+		return -1
+	}
+	return rv.Len()
+}
+
+// Function `mapStr()` renders a map as `key=value` pairs, in key
+// order so that the result is reproducible.
+func mapStr(rv reflect.Value, param *StrConvParams, opts []StrConvOption) string {
+	keys := make([]string, 0, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		keys = append(keys, iter.Key().String())
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		k := reflect.ValueOf(key).Convert(rv.Type().Key())
+		pairs[i] = key + param.pairSep + StrConv(rv.MapIndex(k).Interface(), opts...)
+	}
+	return strings.Join(pairs, param.sep)
+}
+
+// Function `splitPairs()` splits a list of `key=value` pairs at the
+// list separator, except where what follows has no pair separator,
+// which is taken to be part of the previous value, so that `k=a,b`
+// gives `k` the value `a,b`.
+func splitPairs(str string, sep string, pairSep string) []string {
+	items := []string{}
+	for _, item := range strings.Split(str, sep) {
+		if len(items) > 0 && !strings.Contains(item, pairSep) {
+			items[len(items)-1] += sep + item
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// Function `mapFromStr()` parses `key=value` pairs into a map. Every
+// pair is checked before any is set, so a failure leaves the map
+// unchanged.
+func mapFromStr(ix interface{}, str string, doSet bool, param *StrConvParams, opts []StrConvOption) error {
+	rv, _ := mapValue(ix)
+	if reflect.ValueOf(ix).Kind() != reflect.Pointer {
+		return fmt.Errorf("cannot set map through non-pointer %T", ix)
+	}
+	type pair struct {
+		key, value reflect.Value
+	}
+	pairs := []pair{}
+	given := map[string]struct{}{}
+	for _, item := range splitPairs(str, param.sep, param.pairSep) {
+		key, val, ok := strings.Cut(item, param.pairSep)
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("expected key%svalue, got '%s'", param.pairSep, item)
+		}
+		if param.keyCheck != nil {
+			if err := param.keyCheck(key); err != nil {
+				return err
+			}
+		}
+		k := reflect.ValueOf(key).Convert(rv.Type().Key())
+		_, dup := given[key]
+		if param.seenKeys != nil {
+			_, seen := param.seenKeys[key]
+			dup = dup || seen
+		} else if !rv.IsNil() {
+			dup = dup || rv.MapIndex(k).IsValid()
+		}
+		given[key] = struct{}{}
+		if dup {
+			switch param.dupKeys {
+			case DupKeysError:
+				return fmt.Errorf("duplicate key '%s'", key)
+			case DupKeysKeepFirst:
+				continue
+			}
+		}
+		v := reflect.New(rv.Type().Elem())
+		if err := FromStr(v.Interface(), val, true, opts...); err != nil {
+			return fmt.Errorf("invalid value for key '%s': %w", key, err)
+		}
+		pairs = append(pairs, pair{k, v.Elem()})
+	}
+	if !doSet {
+		return nil
+	}
+	if rv.IsNil() {
+		rv.Set(reflect.MakeMap(rv.Type()))
+	}
+	for _, p := range pairs {
+		rv.SetMapIndex(p.key, p.value)
+		if param.seenKeys != nil {
+			param.seenKeys[p.key.String()] = struct{}{}
+		}
+	}
+	return nil
+}
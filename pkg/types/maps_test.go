@@ -0,0 +1,97 @@
+package types
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMapType(t *testing.T) {
+	m := map[string]int{}
+	tp := Type(&m)
+	if !tp.TstMapBit() || !tp.TstPointerBit() || !tp.TstIntBit() || tp.TstSliceBit() {
+		t.Errorf("unexpected type %032b for *map[string]int", tp)
+	}
+	if !IsMap(map[string]time.Duration{}) || !IsDuration(map[string]time.Duration{}) {
+		t.Error("map[string]time.Duration not recognized")
+	}
+	if IsMap(map[int]string{}) || IsMap(map[string][]int{}) || IsMap(&[]string{}) {
+		t.Error("unsupported map recognized")
+	}
+	if !IsOtherT(map[int]string{}) {
+		t.Error("unsupported map not OtherT")
+	}
+}
+
+func TestMapStrConv(t *testing.T) {
+	m := map[string]int{}
+	if err := FromStr(&m, "b=2, a=1", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if StrConv(&m, WithSep(",")) != "a=1,b=2" {
+		t.Errorf("got '%s', expected 'a=1,b=2'", StrConv(&m, WithSep(",")))
+	}
+	if err := FromStr(&m, "a:3;c:4", true, WithSep(";"), WithPairSep(":")); err != nil || m["a"] != 3 || m["c"] != 4 {
+		t.Errorf("got %v (%v), expected a=3, c=4", m, err)
+	}
+	if StrConv(m, WithSep(";"), WithPairSep(":")) != "a:3;b:2;c:4" {
+		t.Errorf("got '%s', expected 'a:3;b:2;c:4'", StrConv(m, WithSep(";"), WithPairSep(":")))
+	}
+	for _, s := range []string{"a", "=1", "a=x", "a=1,b"} {
+		if FromStr(&m, s, true) == nil {
+			t.Errorf("expected an error parsing '%s'", s)
+		}
+	}
+	if len(m) != 3 || m["a"] != 3 {
+		t.Errorf("failed parse changed the map: %v", m)
+	}
+
+	var nilMap map[string]string
+	if err := FromStr(&nilMap, "x=y=z", true); err != nil || nilMap["x"] != "y=z" {
+		t.Errorf("got %v (%v), expected x=y=z", nilMap, err)
+	}
+	// A value can contain the list separator, unless what follows it
+	// looks like another pair
+	if err := FromStr(&nilMap, "k=a,b,c=d", true, WithSep(",")); err != nil ||
+		nilMap["k"] != "a,b" || nilMap["c"] != "d" {
+		t.Errorf("got %v (%v), expected k=a,b and c=d", nilMap, err)
+	}
+	if StrConv(nilMap, WithSep(",")) != "c=d,k=a,b,x=y=z" {
+		t.Errorf("got '%s', expected 'c=d,k=a,b,x=y=z'", StrConv(nilMap, WithSep(",")))
+	}
+	if MapLen(&nilMap) != 3 || MapLen(&[]int{}) != -1 {
+		t.Error("unexpected MapLen() result")
+	}
+}
+
+func TestMapDupKeys(t *testing.T) {
+	m := map[string]string{"a": "0"}
+	if err := FromStr(&m, "a=1,a=2", true); err != nil || m["a"] != "2" {
+		t.Errorf("overwrite: got %v (%v)", m, err)
+	}
+	if err := FromStr(&m, "a=3,b=1,b=2", true, WithDupKeys(DupKeysKeepFirst)); err != nil || m["a"] != "2" || m["b"] != "1" {
+		t.Errorf("keep first: got %v (%v)", m, err)
+	}
+	if FromStr(&m, "c=1,c=2", true, WithDupKeys(DupKeysError)) == nil || len(m) != 2 {
+		t.Errorf("expected an error for a repeated key, got %v", m)
+	}
+	seen := map[string]struct{}{}
+	opts := []StrConvOption{WithDupKeys(DupKeysError), WithSeenKeys(seen)}
+	if err := FromStr(&m, "a=4", true, opts...); err != nil || m["a"] != "4" {
+		t.Errorf("seen keys: got %v (%v)", m, err)
+	}
+	if FromStr(&m, "a=5", true, opts...) == nil || m["a"] != "4" {
+		t.Error("expected an error for a key already seen")
+	}
+
+	bad := errors.New("bad key")
+	check := WithKeyCheck(func(key string) error {
+		if key == "x" {
+			return bad
+		}
+		return nil
+	})
+	if err := FromStr(&m, "y=1,x=1", true, check); !errors.Is(err, bad) || m["y"] != "" {
+		t.Errorf("key check: got %v (%v)", m, err)
+	}
+}
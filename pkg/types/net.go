@@ -0,0 +1,202 @@
+package types
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// A `HostPort` is a host name or IP address and a port number, as
+// given to `net.Dial()` or `net.Listen()`. On the command line, it is
+// `host:port`, `[ipv6]:port`, or, if a default port is given (see
+// `WithDefaultPort()`), just a host or IP address. The host may be
+// empty (e.g. `:8080`), meaning all local addresses to `net.Listen()`.
+type HostPort struct {
+	Host string
+	Port uint16
+}
+
+// Function `String()` renders a `HostPort` as accepted by
+// `net.Dial()`, bracketing IPv6 addresses.
+func (hp HostPort) String() string {
+	return net.JoinHostPort(hp.Host, strconv.FormatUint(uint64(hp.Port), 10))
+}
+
+// Function `ParseHostPort()` parses a `host:port`, using the given
+// default port if the port is omitted or failing if the default is
+// zero.
+func ParseHostPort(s string, defaultPort uint16) (HostPort, error) {
+	host, port := s, ""
+	if strings.HasPrefix(s, "[") {
+		h, p, err := net.SplitHostPort(s)
+		switch {
+		case err == nil:
+			host, port = h, p
+		case strings.HasSuffix(s, "]"):
+			host = s[1 : len(s)-1]
+		default:
+			return HostPort{}, fmt.Errorf("invalid host:port '%s'", s)
+		}
+	} else if i := strings.LastIndexByte(s, ':'); i >= 0 && strings.IndexByte(s, ':') == i {
+		host, port = s[:i], s[i+1:]
+	}
+	// Anything else with a colon must be a bare IPv6 address
+	if strings.IndexByte(host, ':') >= 0 {
+		if _, err := netip.ParseAddr(host); err != nil {
+			return HostPort{}, fmt.Errorf("invalid host '%s' in '%s'", host, s)
+		}
+	}
+	if strings.ContainsAny(host, " \t\n/[]") {
+		return HostPort{}, fmt.Errorf("invalid host '%s' in '%s'", host, s)
+	}
+	if port == "" {
+		if defaultPort == 0 {
+			return HostPort{}, fmt.Errorf("missing port in '%s'", s)
+		}
+		return HostPort{Host: host, Port: defaultPort}, nil
+	}
+	n, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return HostPort{}, fmt.Errorf("invalid port '%s' in '%s'", port, s)
+	}
+	return HostPort{Host: host, Port: uint16(n)}, nil
+}
+
+// An `AddrFamily` restricts the IP addresses accepted (see
+// `WithFamily()`).
+type AddrFamily uint8
+
+const (
+	AnyFamily AddrFamily = iota
+	IPv4Only
+	IPv6Only
+)
+
+func (af AddrFamily) String() string {
+	switch af {
+	case IPv4Only:
+		return "IPv4"
+	case IPv6Only:
+		return "IPv6"
+	}
+	return "IPv4 or IPv6"
+}
+
+// IP addresses (and the addresses in prefixes and address/port pairs)
+// must be of the given family. IPv4-mapped IPv6 addresses count as
+// IPv4.
+func WithFamily(family AddrFamily) StrConvOption {
+	return func(p *StrConvParams) {
+		p.family = family
+	}
+}
+
+// IP addresses must fall within one of the given prefixes and
+// prefixes must be contained in one
+func WithNetworks(networks ...netip.Prefix) StrConvOption {
+	return func(p *StrConvParams) {
+		p.networks = networks
+	}
+}
+
+// A `HostPort` without a port gets the given port
+func WithDefaultPort(port uint16) StrConvOption {
+	return func(p *StrConvParams) {
+		p.defaultPort = port
+	}
+}
+
+// URLs must have one of the given schemes (case-insensitively)
+func WithSchemes(schemes ...string) StrConvOption {
+	return func(p *StrConvParams) {
+		p.schemes = schemes
+	}
+}
+
+func (p *StrConvParams) checkAddr(a netip.Addr, bits int) error {
+	a = a.Unmap()
+	if (p.family == IPv4Only && !a.Is4()) || (p.family == IPv6Only && !a.Is6()) {
+		return fmt.Errorf("address %s is not %s", a, p.family)
+	}
+	if len(p.networks) == 0 {
+		return nil
+	}
+	for _, network := range p.networks {
+		if network.Bits() <= bits && network.Contains(a) {
+			return nil
+		}
+	}
+	strs := make([]string, len(p.networks))
+	for i, network := range p.networks {
+		strs[i] = network.String()
+	}
+	return fmt.Errorf("%s is not within %s", a, strings.Join(strs, ", "))
+}
+
+func (p *StrConvParams) parseIP(s string) (net.IP, error) {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address '%s'", s)
+	}
+	a, _ := netip.AddrFromSlice(ip)
+	return ip, p.checkAddr(a, a.Unmap().BitLen())
+}
+
+func (p *StrConvParams) parseAddr(s string) (netip.Addr, error) {
+	a, err := netip.ParseAddr(s)
+	if err != nil {
+		return a, err
+	}
+	return a, p.checkAddr(a, a.Unmap().BitLen())
+}
+
+func (p *StrConvParams) parsePrefix(s string) (netip.Prefix, error) {
+	pfx, err := netip.ParsePrefix(s)
+	if err != nil {
+		return pfx, err
+	}
+	return pfx, p.checkAddr(pfx.Addr(), pfx.Bits())
+}
+
+func (p *StrConvParams) parseAddrPort(s string) (netip.AddrPort, error) {
+	ap, err := netip.ParseAddrPort(s)
+	if err != nil {
+		return ap, err
+	}
+	return ap, p.checkAddr(ap.Addr(), ap.Addr().Unmap().BitLen())
+}
+
+// Only a host given as an IP address can be checked
+func (p *StrConvParams) parseHostPort(s string) (HostPort, error) {
+	hp, err := ParseHostPort(s, p.defaultPort)
+	if err != nil {
+		return hp, err
+	}
+	if a, err := netip.ParseAddr(hp.Host); err == nil {
+		return hp, p.checkAddr(a, a.Unmap().BitLen())
+	}
+	return hp, nil
+}
+
+func (p *StrConvParams) parseURL(s string) (url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return url.URL{}, err
+	}
+	if len(p.schemes) == 0 {
+		return *u, nil
+	}
+	for _, scheme := range p.schemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return *u, nil
+		}
+	}
+	return *u, fmt.Errorf("scheme '%s' of URL '%s' is not one of %s", u.Scheme, s, strings.Join(p.schemes, ", "))
+}
+
+func formatURL(u url.URL) string {
+	return u.String()
+}
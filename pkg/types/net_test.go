@@ -0,0 +1,113 @@
+package types
+
+import (
+	"net"
+	"net/netip"
+	"net/url"
+	"testing"
+)
+
+func TestParseHostPort(t *testing.T) {
+	testCases := []struct {
+		s    string
+		hp   HostPort
+		back string
+	}{
+		{"example.com:80", HostPort{"example.com", 80}, "example.com:80"},
+		{":8080", HostPort{"", 8080}, ":8080"},
+		{"[::1]:53", HostPort{"::1", 53}, "[::1]:53"},
+		{"example.com", HostPort{"example.com", 443}, "example.com:443"},
+		{"[::1]", HostPort{"::1", 443}, "[::1]:443"},
+		{"fe80::1", HostPort{"fe80::1", 443}, "[fe80::1]:443"},
+		{"10.0.0.1", HostPort{"10.0.0.1", 443}, "10.0.0.1:443"},
+	}
+	for _, tc := range testCases {
+		hp, err := ParseHostPort(tc.s, 443)
+		if err != nil {
+			t.Errorf("unexpected error parsing '%s': %v", tc.s, err)
+			continue
+		}
+		if hp != tc.hp {
+			t.Errorf("parsing '%s': got %+v, expected %+v", tc.s, hp, tc.hp)
+		}
+		if hp.String() != tc.back {
+			t.Errorf("rendering %+v: got '%s', expected '%s'", hp, hp.String(), tc.back)
+		}
+	}
+	for _, s := range []string{"host:http", "host:65536", "[::1", "a:b:c", "a b:1", "[::1]x"} {
+		if _, err := ParseHostPort(s, 443); err == nil {
+			t.Errorf("expected an error parsing '%s'", s)
+		}
+	}
+	if _, err := ParseHostPort("example.com", 0); err == nil {
+		t.Error("expected an error for a missing port without a default")
+	}
+}
+
+func TestNetTypes(t *testing.T) {
+	var ip net.IP
+	if err := FromStr(&ip, "2001:DB8::0001", true); err != nil || StrConv(ip) != "2001:db8::1" {
+		t.Errorf("got %v (%v), expected 2001:db8::1", ip, err)
+	}
+	if FromStr(&ip, "2001:db8::1", true, WithFamily(IPv4Only)) == nil {
+		t.Error("expected an error for IPv6 with IPv4Only")
+	}
+	if err := FromStr(&ip, "::ffff:10.1.2.3", true, WithFamily(IPv4Only)); err != nil {
+		t.Errorf("unexpected error for an IPv4-mapped address: %v", err)
+	}
+
+	tenNet := WithNetworks(netip.MustParsePrefix("10.0.0.0/8"))
+	var addrs []netip.Addr
+	if err := FromStr(&addrs, "10.1.1.1,10.2.2.2", true, WithSep(","), tenNet); err != nil || len(addrs) != 2 {
+		t.Errorf("got %v (%v), expected 2 addresses", addrs, err)
+	}
+	if FromStr(&addrs, "192.168.1.1", true, tenNet) == nil || len(addrs) != 2 {
+		t.Error("expected an error for an address outside the network")
+	}
+
+	var pfx netip.Prefix
+	if err := FromStr(&pfx, "10.1.0.0/16", true, tenNet); err != nil || StrConv(pfx) != "10.1.0.0/16" {
+		t.Errorf("got %v (%v), expected 10.1.0.0/16", pfx, err)
+	}
+	if FromStr(&pfx, "10.0.0.0/7", true, tenNet) == nil {
+		t.Error("expected an error for a prefix larger than the network")
+	}
+
+	var ap netip.AddrPort
+	if err := FromStr(&ap, "[::1]:8080", true, WithFamily(IPv6Only)); err != nil || ap.Port() != 8080 {
+		t.Errorf("got %v (%v), expected [::1]:8080", ap, err)
+	}
+
+	var hp HostPort
+	if err := FromStr(&hp, "localhost", true, WithDefaultPort(8080)); err != nil || StrConv(&hp) != "localhost:8080" {
+		t.Errorf("got %v (%v), expected localhost:8080", hp, err)
+	}
+	if FromStr(&hp, "[::1]:80", true, WithFamily(IPv4Only)) == nil {
+		t.Error("expected an error for an IPv6 host with IPv4Only")
+	}
+
+	var u url.URL
+	web := WithSchemes("http", "https")
+	if err := FromStr(&u, "HTTPS://example.com/a?b=c", true, web); err != nil || StrConv(&u) != "https://example.com/a?b=c" {
+		t.Errorf("got %v (%v), expected https://example.com/a?b=c", StrConv(&u), err)
+	}
+	if FromStr(&u, "ftp://example.com/", true, web) == nil || u.Scheme != "https" {
+		t.Error("expected an error for a disallowed scheme")
+	}
+
+	if !IsNet(&u) || !IsNet([]netip.Prefix{}) || !IsSlice(&addrs) || IsSlice(ip) || IsNet(&hp.Port) {
+		t.Error("type predicates failed")
+	}
+
+	v, err := CoerceScalar(net.IP{}, "10.0.0.1")
+	if err != nil || !Equal(v, net.ParseIP("10.0.0.1").To4()) {
+		t.Errorf("got %v (%v), expected 10.0.0.1", v, err)
+	}
+	v, err = CoerceScalar("", netip.MustParseAddr("::1"))
+	if err != nil || v != "::1" {
+		t.Errorf("got %v (%v), expected '::1'", v, err)
+	}
+	if _, err = CoerceScalar(netip.Addr{}, 1); err == nil {
+		t.Error("expected an error coercing an int to an address")
+	}
+}
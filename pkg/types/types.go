@@ -3,63 +3,100 @@ package types
 import (
 	"bytes"
 	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type SetValue interface {
 	Set(string) error
 }
 
-type TypeId uint16
+// A `BoolSetValue` is a `SetValue` that, like a boolean, takes no
+// argument if `IsBoolFlag()` returns `true`, in which case it is set
+// with "true". This is the same convention used by the standard
+// library's `flag` package.
+type BoolSetValue interface {
+	SetValue
+	IsBoolFlag() bool
+}
+
+type TypeId uint32
 
 const (
-	NumBits  TypeId = 0b0000000000000111
-	Bits8    TypeId = 0x0001
-	Bits16   TypeId = 0x0002
-	Bits32   TypeId = 0x0003
-	Bits64   TypeId = 0x0004
-	BoolT    TypeId = 0b0000000000001000
-	IntT     TypeId = 0b0000000000010000
-	UintT    TypeId = 0b0000000000100000
-	FloatT   TypeId = 0b0000000001000000
-	StringT  TypeId = 0b0000000010000000
-	SliceT   TypeId = 0b0000000100000000
-	PointerT TypeId = 0b0000001000000000
-	SetterT  TypeId = 0b0100000000000000
-	OtherT   TypeId = 0b1000000000000000
+	NumBits   TypeId = 0b0000000000000111
+	Bits8     TypeId = 0x0001
+	Bits16    TypeId = 0x0002
+	Bits32    TypeId = 0x0003
+	Bits64    TypeId = 0x0004
+	BoolT     TypeId = 0b0000000000001000
+	IntT      TypeId = 0b0000000000010000
+	UintT     TypeId = 0b0000000000100000
+	FloatT    TypeId = 0b0000000001000000
+	StringT   TypeId = 0b0000000010000000
+	SliceT    TypeId = 0b0000000100000000
+	PointerT  TypeId = 0b0000001000000000
+	DurationT TypeId = 0b0000010000000000
+	TimeT     TypeId = 0b0000100000000000
+	SizeT     TypeId = 0b0001000000000000
+	NetT      TypeId = 0b0010000000000000
+	SetterT   TypeId = 0b0100000000000000
+	OtherT    TypeId = 0b1000000000000000
+	MapT      TypeId = 0x00010000
+	ArrayT    TypeId = 0x00020000
 )
 
-func (tp *TypeId) SetBoolBit()    { *tp = *tp | BoolT }
-func (tp *TypeId) SetIntBit()     { *tp = *tp | IntT }
-func (tp *TypeId) SetUintBit()    { *tp = *tp | UintT }
-func (tp *TypeId) SetFloatBit()   { *tp = *tp | FloatT }
-func (tp *TypeId) SetStringBit()  { *tp = *tp | StringT }
-func (tp *TypeId) SetSliceBit()   { *tp = *tp | SliceT }
-func (tp *TypeId) SetPointerBit() { *tp = *tp | PointerT }
-func (tp *TypeId) SetSetterBit()  { *tp = *tp | SetterT }
-func (tp *TypeId) SetOtherBit()   { *tp = *tp | OtherT }
-
-func (tp *TypeId) ClrBoolBit()    { *tp = *tp & ^BoolT }
-func (tp *TypeId) ClrIntBit()     { *tp = *tp & ^IntT }
-func (tp *TypeId) ClrUintBit()    { *tp = *tp & ^UintT }
-func (tp *TypeId) ClrFloatBit()   { *tp = *tp & ^FloatT }
-func (tp *TypeId) ClrStringBit()  { *tp = *tp & ^StringT }
-func (tp *TypeId) ClrSliceBit()   { *tp = *tp & ^SliceT }
-func (tp *TypeId) ClrPointerBit() { *tp = *tp & ^PointerT }
-func (tp *TypeId) ClrSetterBit()  { *tp = *tp & ^SetterT }
-func (tp *TypeId) ClrOtherBit()   { *tp = *tp & ^OtherT }
-
-func (tp *TypeId) TstBoolBit() bool    { return *tp&BoolT != 0 }
-func (tp *TypeId) TstIntBit() bool     { return *tp&IntT != 0 }
-func (tp *TypeId) TstUintBit() bool    { return *tp&UintT != 0 }
-func (tp *TypeId) TstFloatBit() bool   { return *tp&FloatT != 0 }
-func (tp *TypeId) TstStringBit() bool  { return *tp&StringT != 0 }
-func (tp *TypeId) TstSliceBit() bool   { return *tp&SliceT != 0 }
-func (tp *TypeId) TstPointerBit() bool { return *tp&PointerT != 0 }
-func (tp *TypeId) TstSetterBit() bool  { return *tp&SetterT != 0 }
-func (tp *TypeId) TstOtherBit() bool   { return *tp&OtherT != 0 }
-func (tp *TypeId) TstAnyNumBit() bool  { return *tp&IntT != 0 || *tp&UintT != 0 || *tp&FloatT != 0 }
+func (tp *TypeId) SetBoolBit()     { *tp = *tp | BoolT }
+func (tp *TypeId) SetIntBit()      { *tp = *tp | IntT }
+func (tp *TypeId) SetUintBit()     { *tp = *tp | UintT }
+func (tp *TypeId) SetFloatBit()    { *tp = *tp | FloatT }
+func (tp *TypeId) SetStringBit()   { *tp = *tp | StringT }
+func (tp *TypeId) SetSliceBit()    { *tp = *tp | SliceT }
+func (tp *TypeId) SetPointerBit()  { *tp = *tp | PointerT }
+func (tp *TypeId) SetDurationBit() { *tp = *tp | DurationT }
+func (tp *TypeId) SetTimeBit()     { *tp = *tp | TimeT }
+func (tp *TypeId) SetSizeBit()     { *tp = *tp | SizeT }
+func (tp *TypeId) SetNetBit()      { *tp = *tp | NetT }
+func (tp *TypeId) SetMapBit()      { *tp = *tp | MapT }
+func (tp *TypeId) SetArrayBit()    { *tp = *tp | ArrayT }
+func (tp *TypeId) SetSetterBit()   { *tp = *tp | SetterT }
+func (tp *TypeId) SetOtherBit()    { *tp = *tp | OtherT }
+
+func (tp *TypeId) ClrBoolBit()     { *tp = *tp & ^BoolT }
+func (tp *TypeId) ClrIntBit()      { *tp = *tp & ^IntT }
+func (tp *TypeId) ClrUintBit()     { *tp = *tp & ^UintT }
+func (tp *TypeId) ClrFloatBit()    { *tp = *tp & ^FloatT }
+func (tp *TypeId) ClrStringBit()   { *tp = *tp & ^StringT }
+func (tp *TypeId) ClrSliceBit()    { *tp = *tp & ^SliceT }
+func (tp *TypeId) ClrPointerBit()  { *tp = *tp & ^PointerT }
+func (tp *TypeId) ClrDurationBit() { *tp = *tp & ^DurationT }
+func (tp *TypeId) ClrTimeBit()     { *tp = *tp & ^TimeT }
+func (tp *TypeId) ClrSizeBit()     { *tp = *tp & ^SizeT }
+func (tp *TypeId) ClrNetBit()      { *tp = *tp & ^NetT }
+func (tp *TypeId) ClrMapBit()      { *tp = *tp & ^MapT }
+func (tp *TypeId) ClrArrayBit()    { *tp = *tp & ^ArrayT }
+func (tp *TypeId) ClrSetterBit()   { *tp = *tp & ^SetterT }
+func (tp *TypeId) ClrOtherBit()    { *tp = *tp & ^OtherT }
+
+func (tp *TypeId) TstBoolBit() bool     { return *tp&BoolT != 0 }
+func (tp *TypeId) TstIntBit() bool      { return *tp&IntT != 0 }
+func (tp *TypeId) TstUintBit() bool     { return *tp&UintT != 0 }
+func (tp *TypeId) TstFloatBit() bool    { return *tp&FloatT != 0 }
+func (tp *TypeId) TstStringBit() bool   { return *tp&StringT != 0 }
+func (tp *TypeId) TstSliceBit() bool    { return *tp&SliceT != 0 }
+func (tp *TypeId) TstPointerBit() bool  { return *tp&PointerT != 0 }
+func (tp *TypeId) TstDurationBit() bool { return *tp&DurationT != 0 }
+func (tp *TypeId) TstTimeBit() bool     { return *tp&TimeT != 0 }
+func (tp *TypeId) TstSizeBit() bool     { return *tp&SizeT != 0 }
+func (tp *TypeId) TstNetBit() bool      { return *tp&NetT != 0 }
+func (tp *TypeId) TstMapBit() bool      { return *tp&MapT != 0 }
+func (tp *TypeId) TstArrayBit() bool    { return *tp&ArrayT != 0 }
+func (tp *TypeId) TstSetterBit() bool   { return *tp&SetterT != 0 }
+func (tp *TypeId) TstOtherBit() bool    { return *tp&OtherT != 0 }
+func (tp *TypeId) TstAnyNumBit() bool   { return *tp&IntT != 0 || *tp&UintT != 0 || *tp&FloatT != 0 }
 
 // Returns true if two types have the same underlying basic type
 func SameBaseType(a, b TypeId) bool {
@@ -222,6 +259,91 @@ func Type(ix interface{}) TypeId {
 	case *[]string:
 		return PointerT | SliceT | StringT
 
+	// Durations, times, and byte sizes
+	case time.Duration:
+		return Bits64 | DurationT
+	case *time.Duration:
+		return PointerT | Bits64 | DurationT
+	case []time.Duration:
+		return SliceT | Bits64 | DurationT
+	case *[]time.Duration:
+		return PointerT | SliceT | Bits64 | DurationT
+
+	case time.Time:
+		return TimeT
+	case *time.Time:
+		return PointerT | TimeT
+	case []time.Time:
+		return SliceT | TimeT
+	case *[]time.Time:
+		return PointerT | SliceT | TimeT
+
+	case ByteSize:
+		return Bits64 | SizeT
+	case *ByteSize:
+		return PointerT | Bits64 | SizeT
+	case []ByteSize:
+		return SliceT | Bits64 | SizeT
+	case *[]ByteSize:
+		return PointerT | SliceT | Bits64 | SizeT
+
+	// Network addresses and URLs
+	case net.IP:
+		return NetT
+	case *net.IP:
+		return PointerT | NetT
+	case []net.IP:
+		return SliceT | NetT
+	case *[]net.IP:
+		return PointerT | SliceT | NetT
+	case netip.Addr:
+		return NetT
+	case *netip.Addr:
+		return PointerT | NetT
+	case []netip.Addr:
+		return SliceT | NetT
+	case *[]netip.Addr:
+		return PointerT | SliceT | NetT
+	case netip.Prefix:
+		return NetT
+	case *netip.Prefix:
+		return PointerT | NetT
+	case []netip.Prefix:
+		return SliceT | NetT
+	case *[]netip.Prefix:
+		return PointerT | SliceT | NetT
+	case netip.AddrPort:
+		return NetT
+	case *netip.AddrPort:
+		return PointerT | NetT
+	case []netip.AddrPort:
+		return SliceT | NetT
+	case *[]netip.AddrPort:
+		return PointerT | SliceT | NetT
+	case HostPort:
+		return NetT
+	case *HostPort:
+		return PointerT | NetT
+	case []HostPort:
+		return SliceT | NetT
+	case *[]HostPort:
+		return PointerT | SliceT | NetT
+	case url.URL:
+		return NetT
+	case *url.URL:
+		return PointerT | NetT
+	case []url.URL:
+		return SliceT | NetT
+	case *[]url.URL:
+		return PointerT | SliceT | NetT
+
+	}
+
+	if typeId, ok := mapType(ix); ok {
+		return typeId
+	}
+	if typeId, ok := arrayType(ix); ok {
+		return typeId
 	}
 
 	// The only useful thing we can do is tell whether the thing
@@ -294,11 +416,50 @@ func IsOtherT(ix interface{}) bool {
 	return typeId.TstOtherBit()
 }
 
+func IsDuration(ix interface{}) bool {
+	typeId := Type(ix)
+	return typeId.TstDurationBit()
+}
+
+func IsTime(ix interface{}) bool {
+	typeId := Type(ix)
+	return typeId.TstTimeBit()
+}
+
+func IsSize(ix interface{}) bool {
+	typeId := Type(ix)
+	return typeId.TstSizeBit()
+}
+
+func IsNet(ix interface{}) bool {
+	typeId := Type(ix)
+	return typeId.TstNetBit()
+}
+
+func IsMap(ix interface{}) bool {
+	typeId := Type(ix)
+	return typeId.TstMapBit()
+}
+
+func IsArray(ix interface{}) bool {
+	typeId := Type(ix)
+	return typeId.TstArrayBit()
+}
+
 func IsSetter(ix interface{}) bool {
 	typeId := Type(ix)
 	return typeId.TstSetterBit()
 }
 
+// Function `IsBoolSetter()` reports whether `ix` implements
+// `BoolSetValue` and behaves like a boolean.
+func IsBoolSetter(ix interface{}) bool {
+	if bs, ok := ix.(BoolSetValue); ok {
+		return bs.IsBoolFlag()
+	}
+	return false
+}
+
 func IsPointer(ix interface{}) bool {
 	typeId := Type(ix)
 	return typeId.TstPointerBit()
@@ -315,7 +476,8 @@ func BitSize(ix interface{}) int {
 	return typeId.BitSize()
 }
 
-// Returns the length of the underlying slice or -1 if not applicable
+// Returns the length of the underlying slice (or array) or -1 if not
+// applicable
 func SliceLen(ix interface{}) int {
 	if ix == nil {
 		return -1
@@ -383,12 +545,51 @@ func SliceLen(ix interface{}) int {
 		return len(v)
 	case *[]string:
 		return len(*v)
+	case []time.Duration:
+		return len(v)
+	case *[]time.Duration:
+		return len(*v)
+	case []time.Time:
+		return len(v)
+	case *[]time.Time:
+		return len(*v)
+	case []ByteSize:
+		return len(v)
+	case *[]ByteSize:
+		return len(*v)
+	case []net.IP:
+		return len(v)
+	case *[]net.IP:
+		return len(*v)
+	case []netip.Addr:
+		return len(v)
+	case *[]netip.Addr:
+		return len(*v)
+	case []netip.Prefix:
+		return len(v)
+	case *[]netip.Prefix:
+		return len(*v)
+	case []netip.AddrPort:
+		return len(v)
+	case *[]netip.AddrPort:
+		return len(*v)
+	case []HostPort:
+		return len(v)
+	case *[]HostPort:
+		return len(*v)
+	case []url.URL:
+		return len(v)
+	case *[]url.URL:
+		return len(*v)
+	}
+	if rv, ok := arrayValue(ix); ok {
+		return rv.Len()
 	}
 	return -1
 }
 
-// Returns the element at index `i` of the underlying slice or nil if
-// not applicable
+// Returns the element at index `i` of the underlying slice (or array)
+// or nil if not applicable
 func ItemAt(ix interface{}, i int) interface{} {
 	if ix == nil {
 		return nil
@@ -528,10 +729,112 @@ func ItemAt(ix interface{}, i int) interface{} {
 		if i < len(*v) {
 			return (*v)[i]
 		}
+
+	case []time.Duration:
+		if i < len(v) {
+			return v[i]
+		}
+	case *[]time.Duration:
+		if i < len(*v) {
+			return (*v)[i]
+		}
+
+	case []time.Time:
+		if i < len(v) {
+			return v[i]
+		}
+	case *[]time.Time:
+		if i < len(*v) {
+			return (*v)[i]
+		}
+
+	case []ByteSize:
+		if i < len(v) {
+			return v[i]
+		}
+	case *[]ByteSize:
+		if i < len(*v) {
+			return (*v)[i]
+		}
+
+	case []net.IP:
+		if i < len(v) {
+			return v[i]
+		}
+	case *[]net.IP:
+		if i < len(*v) {
+			return (*v)[i]
+		}
+
+	case []netip.Addr:
+		if i < len(v) {
+			return v[i]
+		}
+	case *[]netip.Addr:
+		if i < len(*v) {
+			return (*v)[i]
+		}
+
+	case []netip.Prefix:
+		if i < len(v) {
+			return v[i]
+		}
+	case *[]netip.Prefix:
+		if i < len(*v) {
+			return (*v)[i]
+		}
+
+	case []netip.AddrPort:
+		if i < len(v) {
+			return v[i]
+		}
+	case *[]netip.AddrPort:
+		if i < len(*v) {
+			return (*v)[i]
+		}
+
+	case []HostPort:
+		if i < len(v) {
+			return v[i]
+		}
+	case *[]HostPort:
+		if i < len(*v) {
+			return (*v)[i]
+		}
+
+	case []url.URL:
+		if i < len(v) {
+			return v[i]
+		}
+	case *[]url.URL:
+		if i < len(*v) {
+			return (*v)[i]
+		}
+	}
+	if rv, ok := arrayValue(ix); ok && i < rv.Len() {
+		return rv.Index(i).Interface()
 	}
 	return nil
 }
 
+// Returns true if two scalars are equal, comparing times by the
+// instant they represent (see `time.Time.Equal()`), IP addresses by
+// address rather than representation, and URLs by their string form
+func Equal(a, b interface{}) bool {
+	switch va := a.(type) {
+	case time.Time:
+		vb, ok := b.(time.Time)
+		return ok && va.Equal(vb)
+	case net.IP:
+		vb, ok := b.(net.IP)
+		return ok && va.Equal(vb)
+	case url.URL:
+		vb, ok := b.(url.URL)
+		return ok && va.String() == vb.String()
+	}
+	return a == b
+}
+
 // Returns true if ixa is a pointer to the same type as ixb
 func IsPointerTo(ixa, ixb interface{}) bool {
 	ta := Type(ixa)
@@ -548,16 +851,36 @@ func IsPointerTo(ixa, ixb interface{}) bool {
 
 // See the various strconv.Format<Type> functions
 type StrConvParams struct {
-	base int    // for strconv.FormatInt() and .FormatUint()
-	fmt  byte   // for strconv.FormatFloat()
-	prec int    // for strconv.FormatFloat()
-	sep  string // separator for slice elements in returned string
+	base    int      // for strconv.FormatInt() and .FormatUint()
+	fmt     byte     // for strconv.FormatFloat()
+	prec    int      // for strconv.FormatFloat()
+	sep     string   // separator for slice elements in returned string
+	layouts []string // for time.Format() and time.Parse()
+
+	// For network addresses and URLs (see net.go)
+	family      AddrFamily
+	networks    []netip.Prefix
+	defaultPort uint16
+	schemes     []string
+
+	// For integer literals (see ints.go)
+	literals  bool
+	octalZero bool
+	units     bool
+	seenBase  *int
+
+	// For maps (see maps.go)
+	pairSep  string
+	dupKeys  DupKeyPolicy
+	seenKeys map[string]struct{}
+	keyCheck func(key string) error
 }
 
 const baseDefault int = 10
 const fmtDefault byte = byte('g')
 const precDefault int = -1
 const sepDefault string = ", "
+const pairSepDefault string = "="
 
 type StrConvOption = func(f *StrConvParams)
 
@@ -582,17 +905,90 @@ func WithSep(sep string) StrConvOption {
 	}
 }
 
+// Times are formatted with the first layout and parsed with each
+// layout in turn until one succeeds
+func WithLayouts(layouts ...string) StrConvOption {
+	return func(p *StrConvParams) {
+		p.layouts = layouts
+	}
+}
+
+// The layouts used to parse times if none are given with
+// `WithLayouts()`. Times are formatted with the first.
+var TimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// Function `ParseTime()` parses a time with each of the given layouts
+// (or `TimeLayouts`) in turn, returning the first success or the
+// error for the first layout.
+func ParseTime(str string, layouts ...string) (time.Time, error) {
+	if len(layouts) == 0 {
+		layouts = TimeLayouts
+	}
+	var first error
+	for _, layout := range layouts {
+		t, err := time.Parse(layout, str)
+		if err == nil {
+			return t, nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return time.Time{}, first
+}
+
+func (p *StrConvParams) timeLayout() string {
+	if len(p.layouts) == 0 {
+		return TimeLayouts[0]
+	}
+	return p.layouts[0]
+}
+
+func joinStr[T any](items []T, sep string, format func(T) string) string {
+	strs := make([]string, len(items))
+	for i, item := range items {
+		strs[i] = format(item)
+	}
+	return strings.Join(strs, sep)
+}
+
+func appendParsed[T any](v *[]T, str string, sep string, doSet bool, parse func(string) (T, error)) error {
+	for _, item := range strings.Split(str, sep) {
+		x, err := parse(strings.TrimSpace(item))
+		if err != nil {
+			return err
+		}
+		if doSet {
+			*v = append(*v, x)
+		}
+	}
+	return nil
+}
+
 func StrConv(ix interface{}, opts ...StrConvOption) string {
 	param := &StrConvParams{
-		base: baseDefault,
-		fmt:  fmtDefault,
-		prec: precDefault,
-		sep:  sepDefault,
+		base:    baseDefault,
+		fmt:     fmtDefault,
+		prec:    precDefault,
+		sep:     sepDefault,
+		pairSep: pairSepDefault,
 	}
 	for _, opt := range opts {
 		opt(param)
 	}
 
+	if rv, ok := mapValue(ix); ok {
+		return mapStr(rv, param, opts)
+	}
+	if rv, ok := arrayValue(ix); ok {
+		return arrayStr(rv, param, opts)
+	}
+
 	// Return the empty string for any zero-length slice or slice pointer:
 	if SliceLen(ix) == 0 {
 		return ""
@@ -618,154 +1014,154 @@ func StrConv(ix interface{}, opts ...StrConvOption) string {
 
 	// Unsigned integers
 	case uint:
-		return strconv.FormatUint(uint64(v), param.base)
+		return param.formatUint(uint64(v))
 	case *uint:
-		return strconv.FormatUint(uint64(*v), param.base)
+		return param.formatUint(uint64(*v))
 	case []uint:
-		buf.WriteString(strconv.FormatUint(uint64(v[0]), param.base))
+		buf.WriteString(param.formatUint(uint64(v[0])))
 		for _, u := range v[1:] {
-			buf.WriteString(param.sep + strconv.FormatUint(uint64(u), param.base))
+			buf.WriteString(param.sep + param.formatUint(uint64(u)))
 		}
 	case *[]uint:
-		buf.WriteString(strconv.FormatUint(uint64((*v)[0]), param.base))
+		buf.WriteString(param.formatUint(uint64((*v)[0])))
 		for _, u := range (*v)[1:] {
-			buf.WriteString(param.sep + strconv.FormatUint(uint64(u), param.base))
+			buf.WriteString(param.sep + param.formatUint(uint64(u)))
 		}
 
 	case uint8: // also `byte`
-		return strconv.FormatUint(uint64(v), param.base)
+		return param.formatUint(uint64(v))
 	case *uint8:
-		return strconv.FormatUint(uint64(*v), param.base)
+		return param.formatUint(uint64(*v))
 	case []uint8:
-		buf.WriteString(strconv.FormatUint(uint64(v[0]), param.base))
+		buf.WriteString(param.formatUint(uint64(v[0])))
 		for _, u := range v[1:] {
-			buf.WriteString(param.sep + strconv.FormatUint(uint64(u), param.base))
+			buf.WriteString(param.sep + param.formatUint(uint64(u)))
 		}
 	case *[]uint8:
-		buf.WriteString(strconv.FormatUint(uint64((*v)[0]), param.base))
+		buf.WriteString(param.formatUint(uint64((*v)[0])))
 		for _, u := range (*v)[1:] {
-			buf.WriteString(param.sep + strconv.FormatUint(uint64(u), param.base))
+			buf.WriteString(param.sep + param.formatUint(uint64(u)))
 		}
 
 	case uint16:
-		return strconv.FormatUint(uint64(v), param.base)
+		return param.formatUint(uint64(v))
 	case *uint16:
-		return strconv.FormatUint(uint64(*v), param.base)
+		return param.formatUint(uint64(*v))
 	case []uint16:
-		buf.WriteString(strconv.FormatUint(uint64(v[0]), param.base))
+		buf.WriteString(param.formatUint(uint64(v[0])))
 		for _, u := range v[1:] {
-			buf.WriteString(param.sep + strconv.FormatUint(uint64(u), param.base))
+			buf.WriteString(param.sep + param.formatUint(uint64(u)))
 		}
 	case *[]uint16:
-		buf.WriteString(strconv.FormatUint(uint64((*v)[0]), param.base))
+		buf.WriteString(param.formatUint(uint64((*v)[0])))
 		for _, u := range (*v)[1:] {
-			buf.WriteString(param.sep + strconv.FormatUint(uint64(u), param.base))
+			buf.WriteString(param.sep + param.formatUint(uint64(u)))
 		}
 
 	case uint32:
-		return strconv.FormatUint(uint64(v), param.base)
+		return param.formatUint(uint64(v))
 	case *uint32:
-		return strconv.FormatUint(uint64(*v), param.base)
+		return param.formatUint(uint64(*v))
 	case []uint32:
-		buf.WriteString(strconv.FormatUint(uint64(v[0]), param.base))
+		buf.WriteString(param.formatUint(uint64(v[0])))
 		for _, u := range v[1:] {
-			buf.WriteString(param.sep + strconv.FormatUint(uint64(u), param.base))
+			buf.WriteString(param.sep + param.formatUint(uint64(u)))
 		}
 	case *[]uint32:
-		buf.WriteString(strconv.FormatUint(uint64((*v)[0]), param.base))
+		buf.WriteString(param.formatUint(uint64((*v)[0])))
 		for _, u := range (*v)[1:] {
-			buf.WriteString(param.sep + strconv.FormatUint(uint64(u), param.base))
+			buf.WriteString(param.sep + param.formatUint(uint64(u)))
 		}
 
 	case uint64:
-		return strconv.FormatUint(uint64(v), param.base)
+		return param.formatUint(uint64(v))
 	case *uint64:
-		return strconv.FormatUint(uint64(*v), param.base)
+		return param.formatUint(uint64(*v))
 	case []uint64:
-		buf.WriteString(strconv.FormatUint(uint64(v[0]), param.base))
+		buf.WriteString(param.formatUint(uint64(v[0])))
 		for _, u := range v[1:] {
-			buf.WriteString(param.sep + strconv.FormatUint(uint64(u), param.base))
+			buf.WriteString(param.sep + param.formatUint(uint64(u)))
 		}
 	case *[]uint64:
-		buf.WriteString(strconv.FormatUint(uint64((*v)[0]), param.base))
+		buf.WriteString(param.formatUint(uint64((*v)[0])))
 		for _, u := range (*v)[1:] {
-			buf.WriteString(param.sep + strconv.FormatUint(uint64(u), param.base))
+			buf.WriteString(param.sep + param.formatUint(uint64(u)))
 		}
 
 	// Signed integers
 	case int:
-		return strconv.FormatInt(int64(v), param.base)
+		return param.formatInt(int64(v))
 	case *int:
-		return strconv.FormatInt(int64(*v), param.base)
+		return param.formatInt(int64(*v))
 	case []int:
-		buf.WriteString(strconv.FormatInt(int64(v[0]), param.base))
+		buf.WriteString(param.formatInt(int64(v[0])))
 		for _, u := range v[1:] {
-			buf.WriteString(param.sep + strconv.FormatInt(int64(u), param.base))
+			buf.WriteString(param.sep + param.formatInt(int64(u)))
 		}
 	case *[]int:
-		buf.WriteString(strconv.FormatInt(int64((*v)[0]), param.base))
+		buf.WriteString(param.formatInt(int64((*v)[0])))
 		for _, u := range (*v)[1:] {
-			buf.WriteString(param.sep + strconv.FormatInt(int64(u), param.base))
+			buf.WriteString(param.sep + param.formatInt(int64(u)))
 		}
 
 	case int8:
-		return strconv.FormatInt(int64(v), param.base)
+		return param.formatInt(int64(v))
 	case *int8:
-		return strconv.FormatInt(int64(*v), param.base)
+		return param.formatInt(int64(*v))
 	case []int8:
-		buf.WriteString(strconv.FormatInt(int64(v[0]), param.base))
+		buf.WriteString(param.formatInt(int64(v[0])))
 		for _, u := range v[1:] {
-			buf.WriteString(param.sep + strconv.FormatInt(int64(u), param.base))
+			buf.WriteString(param.sep + param.formatInt(int64(u)))
 		}
 	case *[]int8:
-		buf.WriteString(strconv.FormatInt(int64((*v)[0]), param.base))
+		buf.WriteString(param.formatInt(int64((*v)[0])))
 		for _, u := range (*v)[1:] {
-			buf.WriteString(param.sep + strconv.FormatInt(int64(u), param.base))
+			buf.WriteString(param.sep + param.formatInt(int64(u)))
 		}
 
 	case int16:
-		return strconv.FormatInt(int64(v), param.base)
+		return param.formatInt(int64(v))
 	case *int16:
-		return strconv.FormatInt(int64(*v), param.base)
+		return param.formatInt(int64(*v))
 	case []int16:
-		buf.WriteString(strconv.FormatInt(int64(v[0]), param.base))
+		buf.WriteString(param.formatInt(int64(v[0])))
 		for _, u := range v[1:] {
-			buf.WriteString(param.sep + strconv.FormatInt(int64(u), param.base))
+			buf.WriteString(param.sep + param.formatInt(int64(u)))
 		}
 	case *[]int16:
-		buf.WriteString(strconv.FormatInt(int64((*v)[0]), param.base))
+		buf.WriteString(param.formatInt(int64((*v)[0])))
 		for _, u := range (*v)[1:] {
-			buf.WriteString(param.sep + strconv.FormatInt(int64(u), param.base))
+			buf.WriteString(param.sep + param.formatInt(int64(u)))
 		}
 
 	case int32: // also `rune`
-		return strconv.FormatInt(int64(v), param.base)
+		return param.formatInt(int64(v))
 	case *int32:
-		return strconv.FormatInt(int64(*v), param.base)
+		return param.formatInt(int64(*v))
 	case []int32:
-		buf.WriteString(strconv.FormatInt(int64(v[0]), param.base))
+		buf.WriteString(param.formatInt(int64(v[0])))
 		for _, u := range v[1:] {
-			buf.WriteString(param.sep + strconv.FormatInt(int64(u), param.base))
+			buf.WriteString(param.sep + param.formatInt(int64(u)))
 		}
 	case *[]int32:
-		buf.WriteString(strconv.FormatInt(int64((*v)[0]), param.base))
+		buf.WriteString(param.formatInt(int64((*v)[0])))
 		for _, u := range (*v)[1:] {
-			buf.WriteString(param.sep + strconv.FormatInt(int64(u), param.base))
+			buf.WriteString(param.sep + param.formatInt(int64(u)))
 		}
 
 	case int64:
-		return strconv.FormatInt(int64(v), param.base)
+		return param.formatInt(int64(v))
 	case *int64:
-		return strconv.FormatInt(int64(*v), param.base)
+		return param.formatInt(int64(*v))
 	case []int64:
-		buf.WriteString(strconv.FormatInt(int64(v[0]), param.base))
+		buf.WriteString(param.formatInt(int64(v[0])))
 		for _, u := range v[1:] {
-			buf.WriteString(param.sep + strconv.FormatInt(int64(u), param.base))
+			buf.WriteString(param.sep + param.formatInt(int64(u)))
 		}
 	case *[]int64:
-		buf.WriteString(strconv.FormatInt(int64((*v)[0]), param.base))
+		buf.WriteString(param.formatInt(int64((*v)[0])))
 		for _, u := range (*v)[1:] {
-			buf.WriteString(param.sep + strconv.FormatInt(int64(u), param.base))
+			buf.WriteString(param.sep + param.formatInt(int64(u)))
 		}
 
 	// Floating-point types
@@ -808,6 +1204,84 @@ func StrConv(ix interface{}, opts ...StrConvOption) string {
 		return strings.Join(v, param.sep)
 	case *[]string:
 		return strings.Join(*v, param.sep)
+
+	// Durations, times, and byte sizes
+	case time.Duration:
+		return v.String()
+	case *time.Duration:
+		return v.String()
+	case []time.Duration:
+		return joinStr(v, param.sep, time.Duration.String)
+	case *[]time.Duration:
+		return joinStr(*v, param.sep, time.Duration.String)
+
+	case time.Time:
+		return v.Format(param.timeLayout())
+	case *time.Time:
+		return v.Format(param.timeLayout())
+	case []time.Time:
+		return joinStr(v, param.sep, func(t time.Time) string { return t.Format(param.timeLayout()) })
+	case *[]time.Time:
+		return joinStr(*v, param.sep, func(t time.Time) string { return t.Format(param.timeLayout()) })
+
+	case ByteSize:
+		return v.String()
+	case *ByteSize:
+		return v.String()
+	case []ByteSize:
+		return joinStr(v, param.sep, ByteSize.String)
+	case *[]ByteSize:
+		return joinStr(*v, param.sep, ByteSize.String)
+
+	// Network addresses and URLs
+	case net.IP:
+		return v.String()
+	case *net.IP:
+		return v.String()
+	case []net.IP:
+		return joinStr(v, param.sep, net.IP.String)
+	case *[]net.IP:
+		return joinStr(*v, param.sep, net.IP.String)
+	case netip.Addr:
+		return v.String()
+	case *netip.Addr:
+		return v.String()
+	case []netip.Addr:
+		return joinStr(v, param.sep, netip.Addr.String)
+	case *[]netip.Addr:
+		return joinStr(*v, param.sep, netip.Addr.String)
+	case netip.Prefix:
+		return v.String()
+	case *netip.Prefix:
+		return v.String()
+	case []netip.Prefix:
+		return joinStr(v, param.sep, netip.Prefix.String)
+	case *[]netip.Prefix:
+		return joinStr(*v, param.sep, netip.Prefix.String)
+	case netip.AddrPort:
+		return v.String()
+	case *netip.AddrPort:
+		return v.String()
+	case []netip.AddrPort:
+		return joinStr(v, param.sep, netip.AddrPort.String)
+	case *[]netip.AddrPort:
+		return joinStr(*v, param.sep, netip.AddrPort.String)
+	case HostPort:
+		return v.String()
+	case *HostPort:
+		return v.String()
+	case []HostPort:
+		return joinStr(v, param.sep, HostPort.String)
+	case *[]HostPort:
+		return joinStr(*v, param.sep, HostPort.String)
+	case url.URL:
+		return formatURL(v)
+	case *url.URL:
+		return v.String()
+	case []url.URL:
+		return joinStr(v, param.sep, formatURL)
+	case *[]url.URL:
+		return joinStr(*v, param.sep, formatURL)
 	}
 
 	return buf.String()
@@ -823,14 +1297,20 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 	}
 
 	param := &StrConvParams{
-		base: baseDefault,
-		sep:  ",",
+		base:    baseDefault,
+		sep:     ",",
+		pairSep: pairSepDefault,
 		// `fmt` and `prec` are ignored
 	}
 	for _, opt := range opts {
 		opt(param)
 	}
 
+	if !doSet {
+		// Only the base of a value actually set is of interest
+		param.seenBase = nil
+	}
+
 	typeId := Type(ix)
 	if typeId.TstOtherBit() {
 		return fmt.Errorf("interface (%v) does not represent a supported type (%T)", ix, ix)
@@ -838,6 +1318,12 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 	if !typeId.TstPointerBit() {
 		return fmt.Errorf("interface (%v) does not represent a pointer (%T)", ix, ix)
 	}
+	if typeId.TstMapBit() {
+		return mapFromStr(ix, str, doSet, param, opts)
+	}
+	if typeId.TstArrayBit() {
+		return arrayFromStr(ix, str, doSet, param, opts)
+	}
 
 	switch v := ix.(type) {
 	// Booleans
@@ -865,7 +1351,7 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 
 	// Unsigned integers
 	case *uint:
-		u64, err := strconv.ParseUint(str, param.base, strconv.IntSize)
+		u64, err := param.parseUint(str, strconv.IntSize)
 		if err != nil {
 			return err
 		}
@@ -876,7 +1362,7 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 	case *[]uint:
 		for _, item := range strings.Split(str, param.sep) {
 			trimmed := strings.TrimSpace(item)
-			u64, err := strconv.ParseUint(trimmed, param.base, strconv.IntSize)
+			u64, err := param.parseUint(trimmed, strconv.IntSize)
 			if err != nil {
 				return err
 			}
@@ -887,7 +1373,7 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 		return nil
 
 	case *uint8: // also `byte`:
-		u64, err := strconv.ParseUint(str, param.base, 8)
+		u64, err := param.parseUint(str, 8)
 		if err != nil {
 			return err
 		}
@@ -898,7 +1384,7 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 	case *[]uint8:
 		for _, item := range strings.Split(str, param.sep) {
 			trimmed := strings.TrimSpace(item)
-			u64, err := strconv.ParseUint(trimmed, param.base, 8)
+			u64, err := param.parseUint(trimmed, 8)
 			if err != nil {
 				return err
 			}
@@ -909,7 +1395,7 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 		return nil
 
 	case *uint16:
-		u64, err := strconv.ParseUint(str, param.base, 16)
+		u64, err := param.parseUint(str, 16)
 		if err != nil {
 			return err
 		}
@@ -920,7 +1406,7 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 	case *[]uint16:
 		for _, item := range strings.Split(str, param.sep) {
 			trimmed := strings.TrimSpace(item)
-			u64, err := strconv.ParseUint(trimmed, param.base, 16)
+			u64, err := param.parseUint(trimmed, 16)
 			if err != nil {
 				return err
 			}
@@ -931,7 +1417,7 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 		return nil
 
 	case *uint32:
-		u64, err := strconv.ParseUint(str, param.base, 32)
+		u64, err := param.parseUint(str, 32)
 		if err != nil {
 			return err
 		}
@@ -942,7 +1428,7 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 	case *[]uint32:
 		for _, item := range strings.Split(str, param.sep) {
 			trimmed := strings.TrimSpace(item)
-			u64, err := strconv.ParseUint(trimmed, param.base, 32)
+			u64, err := param.parseUint(trimmed, 32)
 			if err != nil {
 				return err
 			}
@@ -953,7 +1439,7 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 		return nil
 
 	case *uint64:
-		u64, err := strconv.ParseUint(str, param.base, 64)
+		u64, err := param.parseUint(str, 64)
 		if err != nil {
 			return err
 		}
@@ -964,7 +1450,7 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 	case *[]uint64:
 		for _, item := range strings.Split(str, param.sep) {
 			trimmed := strings.TrimSpace(item)
-			u64, err := strconv.ParseUint(trimmed, param.base, 64)
+			u64, err := param.parseUint(trimmed, 64)
 			if err != nil {
 				return err
 			}
@@ -976,7 +1462,7 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 
 	// Signed integers
 	case *int:
-		i64, err := strconv.ParseInt(str, param.base, strconv.IntSize)
+		i64, err := param.parseInt(str, strconv.IntSize)
 		if err != nil {
 			return err
 		}
@@ -987,7 +1473,7 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 	case *[]int:
 		for _, item := range strings.Split(str, param.sep) {
 			trimmed := strings.TrimSpace(item)
-			i64, err := strconv.ParseInt(trimmed, param.base, strconv.IntSize)
+			i64, err := param.parseInt(trimmed, strconv.IntSize)
 			if err != nil {
 				return err
 			}
@@ -998,7 +1484,7 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 		return nil
 
 	case *int8:
-		i64, err := strconv.ParseInt(str, param.base, 8)
+		i64, err := param.parseInt(str, 8)
 		if err != nil {
 			return err
 		}
@@ -1009,7 +1495,7 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 	case *[]int8:
 		for _, item := range strings.Split(str, param.sep) {
 			trimmed := strings.TrimSpace(item)
-			i64, err := strconv.ParseInt(trimmed, param.base, 8)
+			i64, err := param.parseInt(trimmed, 8)
 			if err != nil {
 				return err
 			}
@@ -1020,7 +1506,7 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 		return nil
 
 	case *int16:
-		i64, err := strconv.ParseInt(str, param.base, 16)
+		i64, err := param.parseInt(str, 16)
 		if err != nil {
 			return err
 		}
@@ -1031,7 +1517,7 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 	case *[]int16:
 		for _, item := range strings.Split(str, param.sep) {
 			trimmed := strings.TrimSpace(item)
-			i64, err := strconv.ParseInt(trimmed, param.base, 16)
+			i64, err := param.parseInt(trimmed, 16)
 			if err != nil {
 				return err
 			}
@@ -1042,7 +1528,7 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 		return nil
 
 	case *int32: // also `rune`
-		i64, err := strconv.ParseInt(str, param.base, 32)
+		i64, err := param.parseInt(str, 32)
 		if err != nil {
 			return err
 		}
@@ -1053,7 +1539,7 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 	case *[]int32:
 		for _, item := range strings.Split(str, param.sep) {
 			trimmed := strings.TrimSpace(item)
-			i64, err := strconv.ParseInt(trimmed, param.base, 32)
+			i64, err := param.parseInt(trimmed, 32)
 			if err != nil {
 				return err
 			}
@@ -1064,7 +1550,7 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 		return nil
 
 	case *int64:
-		i64, err := strconv.ParseInt(str, param.base, 64)
+		i64, err := param.parseInt(str, 64)
 		if err != nil {
 			return err
 		}
@@ -1075,7 +1561,7 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 	case *[]int64:
 		for _, item := range strings.Split(str, param.sep) {
 			trimmed := strings.TrimSpace(item)
-			i64, err := strconv.ParseInt(trimmed, param.base, 64)
+			i64, err := param.parseInt(trimmed, 64)
 			if err != nil {
 				return err
 			}
@@ -1145,6 +1631,113 @@ func FromStr(ix interface{}, str string, doSet bool, opts ...StrConvOption) erro
 		}
 		return nil
 
+	// Durations, times, and byte sizes
+	case *time.Duration:
+		d, err := time.ParseDuration(str)
+		if err != nil {
+			return err
+		}
+		if doSet {
+			*v = d
+		}
+		return nil
+	case *[]time.Duration:
+		return appendParsed(v, str, param.sep, doSet, time.ParseDuration)
+
+	case *time.Time:
+		t, err := ParseTime(str, param.layouts...)
+		if err != nil {
+			return err
+		}
+		if doSet {
+			*v = t
+		}
+		return nil
+	case *[]time.Time:
+		return appendParsed(v, str, param.sep, doSet, func(s string) (time.Time, error) {
+			return ParseTime(s, param.layouts...)
+		})
+
+	case *ByteSize:
+		b, err := ParseByteSize(str)
+		if err != nil {
+			return err
+		}
+		if doSet {
+			*v = b
+		}
+		return nil
+	case *[]ByteSize:
+		return appendParsed(v, str, param.sep, doSet, ParseByteSize)
+
+	// Network addresses and URLs
+	case *net.IP:
+		x, err := param.parseIP(str)
+		if err != nil {
+			return err
+		}
+		if doSet {
+			*v = x
+		}
+		return nil
+	case *[]net.IP:
+		return appendParsed(v, str, param.sep, doSet, param.parseIP)
+	case *netip.Addr:
+		x, err := param.parseAddr(str)
+		if err != nil {
+			return err
+		}
+		if doSet {
+			*v = x
+		}
+		return nil
+	case *[]netip.Addr:
+		return appendParsed(v, str, param.sep, doSet, param.parseAddr)
+	case *netip.Prefix:
+		x, err := param.parsePrefix(str)
+		if err != nil {
+			return err
+		}
+		if doSet {
+			*v = x
+		}
+		return nil
+	case *[]netip.Prefix:
+		return appendParsed(v, str, param.sep, doSet, param.parsePrefix)
+	case *netip.AddrPort:
+		x, err := param.parseAddrPort(str)
+		if err != nil {
+			return err
+		}
+		if doSet {
+			*v = x
+		}
+		return nil
+	case *[]netip.AddrPort:
+		return appendParsed(v, str, param.sep, doSet, param.parseAddrPort)
+	case *HostPort:
+		x, err := param.parseHostPort(str)
+		if err != nil {
+			return err
+		}
+		if doSet {
+			*v = x
+		}
+		return nil
+	case *[]HostPort:
+		return appendParsed(v, str, param.sep, doSet, param.parseHostPort)
+	case *url.URL:
+		x, err := param.parseURL(str)
+		if err != nil {
+			return err
+		}
+		if doSet {
+			*v = x
+		}
+		return nil
+	case *[]url.URL:
+		return appendParsed(v, str, param.sep, doSet, param.parseURL)
+
 	}
 	return nil
 }
@@ -1,8 +1,12 @@
 package types
 
 import (
+	"net"
+	"net/netip"
+	"net/url"
 	"strconv"
 	"testing"
+	"time"
 )
 
 type PSet struct {
@@ -231,3 +235,70 @@ func TestIsPointerTo(t *testing.T) {
 		t.Errorf("got failure, expected success")
 	}
 }
+
+type boolSetter struct {
+	b    bool
+	bool bool
+}
+
+func (bs *boolSetter) Set(s string) error {
+	var err error
+	bs.b, err = strconv.ParseBool(s)
+	return err
+}
+
+func (bs *boolSetter) IsBoolFlag() bool {
+	return bs.bool
+}
+
+func TestIsBoolSetter(t *testing.T) {
+	if !IsBoolSetter(&boolSetter{bool: true}) {
+		t.Error("bool setter not recognized")
+	}
+	if IsBoolSetter(&boolSetter{bool: false}) {
+		t.Error("non-bool setter recognized as bool")
+	}
+	b := true
+	if IsBoolSetter(&b) {
+		t.Error("*bool recognized as bool setter")
+	}
+	if !IsSetter(&boolSetter{}) {
+		t.Error("bool setter not recognized as setter")
+	}
+}
+
+func FuzzFromStr(f *testing.F) {
+	for _, s := range []string{"", "0", "-1", "0x1f", "1K", "1.5e3", "true",
+		"1h30m", "2024-02-29", "a=1,b=2", "1,2,3", "[::1]:80", "10.0.0.0/8",
+		"http://host/path", "\xff", "=", ",", "0b", "-0o"} {
+		f.Add(s, uint8(0))
+		f.Add(s, uint8(7))
+	}
+	values := func() []interface{} {
+		return []interface{}{
+			new(bool), new(int), new(int8), new(uint), new(uint8),
+			new(int64), new(uint64), new(float32), new(float64),
+			new(string), new(time.Duration), new(time.Time),
+			new(ByteSize), new(HostPort), new(net.IP), new(netip.Addr),
+			new(netip.Prefix), new(url.URL), new([]int), new([]string),
+			new([]float64), new([3]int), new(map[string]int),
+			new(map[string]string),
+		}
+	}
+	f.Fuzz(func(t *testing.T, s string, flags uint8) {
+		opts := []StrConvOption{}
+		if flags&1 != 0 {
+			opts = append(opts, WithLiterals(flags&2 != 0))
+		}
+		if flags&4 != 0 {
+			opts = append(opts, WithUnits())
+		}
+		for _, v := range values() {
+			tested := FromStr(v, s, false, opts...)
+			err := FromStr(v, s, true, opts...)
+			if (tested == nil) != (err == nil) {
+				t.Errorf("%T: testing '%s' gave %v, setting gave %v", v, s, tested, err)
+			}
+		}
+	})
+}
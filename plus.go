@@ -0,0 +1,119 @@
+package fflag
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/EmmetCaulfield/fflag/pkg/types"
+)
+
+// Option `WithPlusOptions()` lets flags given `PlusNegates()` be
+// turned off with a plus instead of a hyphen, as in `set +x` or
+// `xterm +sb`. A short flag, a cluster of short flags (e.g. `+eu`), or
+// a long flag, possibly abbreviated, can follow the plus. Any other
+// argument beginning with a plus that isn't the +NUM idiom (see
+// `WithPlusNum()`) is then an error rather than an operand.
+func WithPlusOptions() FlagSetOption {
+	return func(fs *FlagSet) {
+		fs.PlusOptions = true
+	}
+}
+
+// Option `PlusNegates()` lets a flag be turned off with a plus (e.g.
+// `+x`) when the `FlagSet` accepts plus options (see
+// `WithPlusOptions()`). A boolean flag is set to false, otherwise an
+// "unset" callback must be given, which is called with an empty
+// argument. Help/usage text shows the plus form (e.g. `-x, +x`).
+func PlusNegates(unset ...CallbackFunction) FlagOption {
+	return func(f *Flag) error {
+		if len(unset) > 1 {
+			log.Panicf("more than one unset callback for '%s'", f)
+		}
+		if len(unset) == 1 && unset[0] != nil {
+			f.UnsetCallback = unset[0]
+		} else if f.HasCallback() || !f.IsScalar() || !types.IsBool(f.Value) {
+			log.Panicf("an unset callback is required to negate non-boolean '%s'", f)
+		}
+		f.Type.SetPlusNegatesBit()
+		return nil
+	}
+}
+
+func (f *Flag) IsNegatable() bool {
+	if f.AliasFor != nil {
+		f = f.AliasFor
+	}
+	return f.Type.TstPlusNegatesBit()
+}
+
+// Function `Unset()` turns off a flag given `PlusNegates()`, either by
+// calling its unset callback or by setting it to false, without
+// counting it as a repeat.
+func (f *Flag) Unset(argPos int) error {
+	if f.AliasFor != nil {
+		f = f.AliasFor
+	}
+	if !f.IsNegatable() {
+		return &FlagError{"flag cannot be negated"}
+	}
+	if prev := f.MutexCollides(); prev != nil {
+		return f.failf(true, &FlagError{"mutex collision in Flag.Unset()"},
+			"flag '%s' conflicts with previously given flag '%s'", f, prev)
+	}
+	if f.UnsetCallback != nil {
+		return f.UnsetCallback(f, "", argPos)
+	}
+	if setter, ok := f.Value.(types.SetValue); ok {
+		return setter.Set("false")
+	}
+	return types.FromStr(f.Value, "false", true)
+}
+
+// Function `plusFlags()` resolves the flags following a plus, which
+// are a long flag, possibly abbreviated, or a cluster of short flags,
+// all of which must be negatable.
+func (fs *FlagSet) plusFlags(flags string) ([]*Flag, error) {
+	if f := fs.Lookup(flags); f != nil {
+		if !f.IsNegatable() {
+			return nil, fmt.Errorf("flag '%s' cannot be negated with '+'", f)
+		}
+		return []*Flag{f}, nil
+	}
+	list := []*Flag{}
+	for _, r := range flags {
+		f := fs.LookupShort(r)
+		if f == nil {
+			return nil, fmt.Errorf("flag '+%s' not defined", flags)
+		}
+		if !f.IsNegatable() {
+			return nil, fmt.Errorf("flag '%s' cannot be negated with '+'", f)
+		}
+		list = append(list, f)
+	}
+	return list, nil
+}
+
+// Function `isPlusOption()` reports whether an argument is a plus
+// option that will be accepted.
+func (fs *FlagSet) isPlusOption(flags string, argType ArgMask) bool {
+	if !fs.PlusOptions || !argType.TstPlusBit() {
+		return false
+	}
+	_, err := fs.plusFlags(flags)
+	return err == nil
+}
+
+// Function `unsetFlags()` turns off the flags following a plus.
+func (fs *FlagSet) unsetFlags(flags string, pos int) {
+	list, err := fs.plusFlags(flags)
+	if err != nil {
+		fs.Failf("%v", err)
+		return
+	}
+	for _, f := range list {
+		err = f.Unset(pos)
+		if err != nil {
+			fs.Failf("failed to unset '%s': %v", f, err)
+		}
+	}
+}
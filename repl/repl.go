@@ -0,0 +1,262 @@
+// Package `repl` turns the flag definitions of a `FlagSet` into the
+// commands of an interactive console. Each line read is split into
+// arguments with shell quoting rules (see package `shlex`), the
+// `FlagSet` is reset and the arguments parsed into it, and then a
+// handler is called with the `FlagSet` and the operands.
+//
+// A line consisting of the single word `help` prints the flag
+// descriptions instead of being parsed, and `Complete()` provides
+// candidates for tab completion from the long flag names and the
+// values of enum-style defaults.
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/EmmetCaulfield/fflag"
+	"github.com/EmmetCaulfield/fflag/pkg/shlex"
+	"github.com/EmmetCaulfield/fflag/pkg/types"
+)
+
+// A `Handler` is called for every line that parses successfully with
+// the operands left over after parsing. If it returns `ErrQuit`,
+// `Run()` returns `nil` without reading any more lines. Any other
+// error is printed and the next line is read.
+type Handler func(fs *fflag.FlagSet, operands []string) error
+
+// Returned by a `Handler` to end a `Run()`
+var ErrQuit = errors.New("quit")
+
+// The built-in command that prints help
+const HelpCommand = "help"
+
+// A `REPL` reads command lines and executes them against a `FlagSet`.
+type REPL struct {
+	FlagSet    *fflag.FlagSet
+	Handler    Handler
+	Prompt     string
+	ContPrompt string
+	Output     io.Writer
+	Env        map[string]string
+}
+
+// Functional option type for `New()` options.
+type Option = func(r *REPL)
+
+// Option `WithPrompt()` sets the prompt printed before each line, and
+// the prompt printed before continuation lines (where a quoted string
+// or a backslash runs on to the next line).
+func WithPrompt(prompt, cont string) Option {
+	return func(r *REPL) {
+		r.Prompt = prompt
+		r.ContPrompt = cont
+	}
+}
+
+// Option `WithOutput()` sets the writer for prompts, help, and
+// error messages.
+func WithOutput(w io.Writer) Option {
+	return func(r *REPL) {
+		r.Output = w
+	}
+}
+
+// Option `WithEnv()` enables `$VAR` expansion in lines using the
+// values in the given map (see `shlex.WithEnv()`).
+func WithEnv(env map[string]string) Option {
+	return func(r *REPL) {
+		r.Env = env
+	}
+}
+
+// Function `New()` creates a `REPL` over the given `FlagSet`. The
+// values of the flags when `New()` is called (see `FlagSet.Reset()`)
+// are the values every line starts from.
+func New(fs *fflag.FlagSet, handler Handler, opts ...Option) *REPL {
+	r := &REPL{
+		FlagSet:    fs,
+		Handler:    handler,
+		Prompt:     "> ",
+		ContPrompt: "... ",
+		Output:     os.Stdout,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	fs.Snapshot()
+	return r
+}
+
+// Function `Run()` reads and executes lines from `in` until it is
+// exhausted or the handler returns `ErrQuit`. Only an error reading
+// `in` is returned; errors in individual lines are printed to the
+// output and execution continues with the next line.
+func (r *REPL) Run(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	line := ""
+	fmt.Fprint(r.Output, r.Prompt)
+	for scanner.Scan() {
+		line += scanner.Text()
+		args, err := shlex.Split(line, shlex.WithEnv(r.Env))
+		if errors.Is(err, shlex.ErrUnterminatedQuote) || errors.Is(err, shlex.ErrTrailingBackslash) {
+			// Keep reading until the line is complete
+			line += "\n"
+			fmt.Fprint(r.Output, r.ContPrompt)
+			continue
+		}
+		line = ""
+		if err == nil {
+			err = r.exec(args)
+		}
+		if errors.Is(err, ErrQuit) {
+			return nil
+		}
+		if err != nil {
+			fmt.Fprintf(r.Output, "error: %v\n", err)
+		}
+		fmt.Fprint(r.Output, r.Prompt)
+	}
+	if line != "" {
+		fmt.Fprintf(r.Output, "error: incomplete line at end of input\n")
+	}
+	return scanner.Err()
+}
+
+// Function `Exec()` executes a single line, returning any error in
+// splitting or parsing it, or the error returned by the handler.
+func (r *REPL) Exec(line string) error {
+	args, err := shlex.Split(line, shlex.WithEnv(r.Env))
+	if err != nil {
+		return err
+	}
+	return r.exec(args)
+}
+
+func (r *REPL) exec(args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	if len(args) == 1 && args[0] == HelpCommand {
+		r.Help()
+		return nil
+	}
+	err := r.parse(args)
+	if err != nil {
+		return err
+	}
+	if r.Handler == nil {
+		return nil
+	}
+	operands := append([]string{}, []string(*r.FlagSet.OutputArgs)...)
+	return r.Handler(r.FlagSet, operands)
+}
+
+// Function `parse()` parses a line into the `FlagSet`, turning
+// failures (which would otherwise exit the program) into errors.
+func (r *REPL) parse(args []string) (err error) {
+	fs := r.FlagSet
+	saved, savedFile := fs.OnFail, fs.OnFileError
+	for _, onFail := range []*fflag.FailOption{&fs.OnFail, &fs.OnFileError} {
+		onFail.ClrContinueBit()
+		onFail.SetPanicBit()
+		onFail.SetSilentBit()
+	}
+	defer func() {
+		fs.OnFail, fs.OnFileError = saved, savedFile
+		if p := recover(); p != nil {
+			msg, ok := p.(string)
+			if !ok {
+				panic(p)
+			}
+			err = errors.New(msg)
+		}
+	}()
+	fs.Reset()
+	return fs.Parse(args)
+}
+
+// Function `Help()` prints the descriptions of the flags.
+func (r *REPL) Help() {
+	fmt.Fprintln(r.Output, strings.Join(r.FlagSet.AlignedFlagDescriptions("  ", "  ", ""), "\n"))
+}
+
+// Function `Complete()` returns the candidates, in lexicographic
+// order, for completing the last word of a partial line: long flag
+// names for a word beginning with `--`, enum values for the
+// option-argument of a flag with an enum-style default (whether
+// attached with `=` or given as the following word), and the `help`
+// command for the first word.
+func (r *REPL) Complete(line string) []string {
+	words := strings.Fields(line)
+	word := ""
+	if len(words) > 0 && !strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\t") {
+		word = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+	candidates := []string{}
+	fs := r.FlagSet
+
+	switch {
+	case strings.HasPrefix(word, "--") && strings.Contains(word, "="):
+		name, prefix, _ := strings.Cut(word[2:], "=")
+		for _, value := range enumValues(fs.LookupLong(name), prefix) {
+			candidates = append(candidates, "--"+name+"="+value)
+		}
+	case strings.HasPrefix(word, "--") || word == "-":
+		for _, name := range fs.LongTrie.Keys(strings.TrimLeft(word, "-")) {
+			candidates = append(candidates, "--"+name)
+		}
+	case strings.HasPrefix(word, "-"):
+		// Short flags and clusters aren't completed
+	default:
+		if len(words) > 0 {
+			candidates = enumValues(lookupArg(fs, words[len(words)-1]), word)
+		}
+		if len(words) == 0 && strings.HasPrefix(HelpCommand, word) {
+			candidates = append(candidates, HelpCommand)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+// Function `lookupArg()` finds the flag given by a whole word that
+// would take the next word as its option-argument, or returns `nil`.
+func lookupArg(fs *fflag.FlagSet, word string) *fflag.Flag {
+	var f *fflag.Flag
+	switch {
+	case strings.HasPrefix(word, "--") && !strings.Contains(word, "="):
+		f = fs.LookupLong(word[2:])
+	case strings.HasPrefix(word, "-") && len([]rune(word)) == 2:
+		f = fs.LookupShort([]rune(word)[1])
+	}
+	if f == nil || f.IsBool() || f.IsCounter() {
+		return nil
+	}
+	return f
+}
+
+// Function `enumValues()` returns the values of an enum-style default
+// of a flag that begin with the given prefix.
+func enumValues(f *fflag.Flag, prefix string) []string {
+	values := []string{}
+	if f == nil || f.GetDefaultLen() < 2 {
+		return values
+	}
+	if f.AliasFor != nil {
+		f = f.AliasFor
+	}
+	for i := 0; i < types.SliceLen(f.Default); i++ {
+		value := types.StrConv(types.ItemAt(f.Default, i))
+		if strings.HasPrefix(value, prefix) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,151 @@
+package repl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EmmetCaulfield/fflag"
+	"github.com/stretchr/testify/assert"
+)
+
+type replTestValues struct {
+	Verbose bool
+	Name    string
+	Color   string
+	Format  string
+}
+
+func replTestFlagSet(v *replTestValues) *fflag.FlagSet {
+	fflag.PosixEquals = true
+	fflag.PosixDoubleHyphen = true
+	fflag.PosixOperandStop = false
+	v.Color = "never"
+	fs := fflag.NewFlagSet()
+	fs.Var(&v.Verbose, 'v', "verbose", "be noisy")
+	fs.Var(&v.Name, 'n', "name", "a name")
+	fs.Var(&v.Color, fflag.NoShort, "color", "when to use color",
+		fflag.WithOptionalDefault([]string{"auto", "never", "always"}))
+	fs.Var(&v.Format, 'f', "format", "output format",
+		fflag.WithDefault([]string{"text", "json", "jsonl", "yaml"}))
+	return fs
+}
+
+func TestRun(u *testing.T) {
+	t := assert.TestingT(u)
+	var v replTestValues
+	fs := replTestFlagSet(&v)
+	out := &bytes.Buffer{}
+	calls := []string{}
+	handler := func(fs *fflag.FlagSet, operands []string) error {
+		calls = append(calls, fmt.Sprintf("%t %q %s %s %q", v.Verbose, v.Name, v.Color, v.Format, operands))
+		if len(operands) > 0 && operands[0] == "quit" {
+			return ErrQuit
+		}
+		if len(operands) > 0 && operands[0] == "fail" {
+			return errors.New("failed")
+		}
+		return nil
+	}
+	r := New(fs, handler, WithOutput(out), WithPrompt("$ ", "> "))
+	script := strings.Join([]string{
+		"op1 -v --name 'Joe Bloggs' --color",
+		"",
+		"# just a comment",
+		"-f json --name=\"multi",
+		"line\" op2",
+		"--format=xml",
+		"--nope",
+		"fail",
+		"help",
+		"-n x \\",
+		"op3",
+		"quit",
+		"-v",
+	}, "\n")
+	err := r.Run(strings.NewReader(script))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`true "Joe Bloggs" auto text ["op1"]`,
+		`false "multi\nline" never json ["op2"]`,
+		`false "" never text ["fail"]`,
+		`false "x" never text ["op3"]`,
+		`false "" never text ["quit"]`,
+	}, calls)
+
+	output := out.String()
+	assert.True(t, strings.HasPrefix(output, "$ $ $ $ > $ error: "), output)
+	assert.Contains(t, output, "error: failed\n")
+	assert.Contains(t, output, "--format")
+	assert.Contains(t, output, "be noisy")
+	assert.Equal(t, 3, strings.Count(output, "error: "), output)
+	// The `FlagSet`'s own failure handling is untouched
+	assert.Equal(t, fflag.FailDefault, fs.OnFail)
+}
+
+func TestRunIncomplete(u *testing.T) {
+	t := assert.TestingT(u)
+	var v replTestValues
+	out := &bytes.Buffer{}
+	r := New(replTestFlagSet(&v), nil, WithOutput(out))
+	err := r.Run(strings.NewReader("-n 'open"))
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "error: incomplete line")
+}
+
+func TestExec(u *testing.T) {
+	t := assert.TestingT(u)
+	var v replTestValues
+	ops := []string{}
+	r := New(replTestFlagSet(&v), func(fs *fflag.FlagSet, operands []string) error {
+		ops = operands
+		return nil
+	}, WithEnv(map[string]string{"WHO": "world"}))
+	assert.NoError(t, r.Exec("-v hello $WHO"))
+	assert.True(t, v.Verbose)
+	assert.Equal(t, []string{"hello", "world"}, ops)
+	assert.Error(t, r.Exec("-n 'oops"))
+	assert.Error(t, r.Exec("-f csv"))
+	assert.NoError(t, r.Exec("again"))
+	assert.False(t, v.Verbose, "flags are reset for each line")
+
+	// A file that can't be read is an error, not an exit
+	var lines []string
+	r.FlagSet.Var(&lines, 'l', "lines", "read lines from a file", fflag.ReadFile())
+	err := r.Exec("-l " + filepath.Join(u.TempDir(), "missing"))
+	assert.ErrorContains(t, err, "failed to open file")
+	assert.NoError(t, r.Exec("again"))
+}
+
+func TestComplete(u *testing.T) {
+	t := assert.TestingT(u)
+	var v replTestValues
+	r := New(replTestFlagSet(&v), nil)
+	testCases := []struct {
+		line       string
+		candidates []string
+	}{
+		{"", []string{"help"}},
+		{"he", []string{"help"}},
+		{"-", []string{"--color", "--format", "--name", "--verbose"}},
+		{"--", []string{"--color", "--format", "--name", "--verbose"}},
+		{"-v --", []string{"--color", "--format", "--name", "--verbose"}},
+		{"--co", []string{"--color"}},
+		{"--color=", []string{"--color=always", "--color=auto", "--color=never"}},
+		{"--color=a", []string{"--color=always", "--color=auto"}},
+		{"--format=js", []string{"--format=json", "--format=jsonl"}},
+		{"--name=", []string{}},
+		{"-f ", []string{"json", "jsonl", "text", "yaml"}},
+		{"--format y", []string{"yaml"}},
+		{"--verbose ", []string{}},
+		{"-vf", []string{}},
+		{"x ", []string{}},
+		{"--nope=", []string{}},
+	}
+	for _, test := range testCases {
+		assert.Equal(t, test.candidates, r.Complete(test.line), "line: %q", test.line)
+	}
+}
@@ -0,0 +1,156 @@
+package fflag
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// An `ErrorCategory` classifies a failure, which determines how it is
+// handled (see `FailOption`) and the exit code used.
+type ErrorCategory uint8
+
+const (
+	// A mistake on the command line (see `OnFail` and `FailExitCode`)
+	UsageError ErrorCategory = iota
+	// A file given to a `ReadFile()` flag couldn't be read (see
+	// `OnFileError` and `FileErrExitCode`)
+	FileError
+)
+
+// A `Reporter` receives the messages produced by a `FlagSet`: errors,
+// with their category, warnings, information, and the usage text
+// printed by `DumpUsage()`. A `Reporter` only reports: whether to
+// continue, panic, or exit after an error is decided by the
+// `FlagSet`. The default is a `WriterReporter` on the `FlagSet`'s
+// `Output` and `HelpOutput`.
+type Reporter interface {
+	Error(category ErrorCategory, msg string)
+	Warning(msg string)
+	Info(msg string)
+	Usage(text string)
+}
+
+// A `WriterReporter` writes errors, warnings, and information, with
+// a prefix, to one writer and usage text to another.
+type WriterReporter struct {
+	Output     io.Writer
+	HelpOutput io.Writer
+}
+
+func (r *WriterReporter) Error(category ErrorCategory, msg string) {
+	fmt.Fprintf(r.Output, "ERROR: %s\n", msg)
+}
+
+func (r *WriterReporter) Warning(msg string) {
+	fmt.Fprintf(r.Output, "WARNING: %s\n", msg)
+}
+
+func (r *WriterReporter) Info(msg string) {
+	fmt.Fprintf(r.Output, "INFO: %s\n", msg)
+}
+
+func (r *WriterReporter) Usage(text string) {
+	fmt.Fprintln(r.HelpOutput, text)
+}
+
+// Option `WithReporter()` sets the `Reporter` to which messages are
+// given, instead of writing them to `Output` and `HelpOutput`.
+func WithReporter(r Reporter) FlagSetOption {
+	return func(fs *FlagSet) {
+		fs.Reporter = r
+	}
+}
+
+// Option `WithHelpWriter()` sets the writer for usage text printed by
+// `DumpUsage()`, which is standard output by default.
+func WithHelpWriter(w io.Writer) FlagSetOption {
+	return func(fs *FlagSet) {
+		fs.HelpOutput = w
+	}
+}
+
+// Option `WithExitFunc()` sets the function called to exit after a
+// failure, which is `os.Exit()` by default. If the function returns,
+// processing continues as if `WithContinueOnFail()` had been given,
+// so a test can record the exit code instead of exiting.
+func WithExitFunc(exit func(code int)) FlagSetOption {
+	return func(fs *FlagSet) {
+		fs.ExitFunc = exit
+	}
+}
+
+// Option `WithQuiet()` suppresses warnings and information, which,
+// unlike errors, aren't affected by `WithSilentFail()`.
+func WithQuiet() FlagSetOption {
+	return func(fs *FlagSet) {
+		fs.Quiet = true
+	}
+}
+
+// Function `reporter()` returns the `FlagSet`'s `Reporter`, or the
+// default one writing to its writers.
+func (fs *FlagSet) reporter() Reporter {
+	if fs.Reporter != nil {
+		return fs.Reporter
+	}
+	return &WriterReporter{Output: fs.Output, HelpOutput: fs.HelpOutput}
+}
+
+// Function `ExitCode()` returns the exit code for a category of
+// failure.
+func (fs *FlagSet) ExitCode(category ErrorCategory) int {
+	if category == FileError {
+		return fs.FileErrExitCode
+	}
+	return fs.FailExitCode
+}
+
+// Function `fail()` reports a failure in a category and then
+// continues, panics, or exits as its `FailOption` says.
+func (fs *FlagSet) fail(category ErrorCategory, msg string) {
+	onFail := fs.OnFail
+	if category == FileError {
+		onFail = fs.OnFileError
+	}
+	if !onFail.TstSilentBit() {
+		fs.reporter().Error(category, msg)
+	}
+	if onFail.TstContinueBit() {
+		return
+	}
+	if onFail.TstPanicBit() {
+		panic(msg)
+	}
+	exit := fs.ExitFunc
+	if exit == nil {
+		exit = os.Exit
+	}
+	exit(fs.ExitCode(category))
+}
+
+// A `reportedError` wraps an error that a flag has already reported
+// on failing to be set, so that the parser doesn't report it again
+// (see `failSet()`).
+type reportedError struct {
+	err error
+}
+
+func (e *reportedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *reportedError) Unwrap() error {
+	return e.err
+}
+
+// Function `failSet()` reports the error returned on failing to set a
+// flag, unless the flag has already reported it.
+func (fs *FlagSet) failSet(err error, format string, args ...interface{}) {
+	var reported *reportedError
+	if errors.As(err, &reported) {
+		return
+	}
+	fs.Failf(format, args...)
+}
@@ -0,0 +1,123 @@
+package fflag
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testReporter struct {
+	categories []ErrorCategory
+	messages   []string
+}
+
+func (r *testReporter) Error(category ErrorCategory, msg string) {
+	r.categories = append(r.categories, category)
+	r.messages = append(r.messages, msg)
+}
+
+func (r *testReporter) Warning(msg string) { r.messages = append(r.messages, "warning: "+msg) }
+func (r *testReporter) Info(msg string)    { r.messages = append(r.messages, "info: "+msg) }
+func (r *testReporter) Usage(text string)  { r.messages = append(r.messages, "usage: "+text) }
+
+func TestReporter(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixOperandStop = true
+	errs, help := &bytes.Buffer{}, &bytes.Buffer{}
+	codes := []int{}
+	var lines []string
+	var n int
+	fs := NewFlagSet(WithOutputWriter(errs), WithHelpWriter(help),
+		WithExitFunc(func(code int) { codes = append(codes, code) }))
+	fs.FileErrExitCode = 3
+	fs.Var(&lines, 'f', "file", "read lines from a file", ReadFile())
+	fs.Var(&n, 'n', "number", "a number")
+
+	fs.Parse([]string{"-nx", "-f", filepath.Join(u.TempDir(), "missing")})
+	// Each failure is reported once, with the exit code of its category
+	assert.Equal(t, []int{2, 3}, codes, "an exit code per category")
+	assert.Contains(t, errs.String(), "ERROR: failed to open file")
+	assert.Empty(t, help.String())
+
+	fs.DumpUsage()
+	assert.Contains(t, help.String(), "--number=INT")
+
+	// Warnings and information aren't silenced with errors
+	errs.Reset()
+	fs.OnFail.SetSilentBit()
+	fs.Warnf("careful")
+	fs.Infof("note")
+	fs.Failf("oops")
+	assert.Equal(t, "WARNING: careful\nINFO: note\n", errs.String())
+	errs.Reset()
+	fs.Quiet = true
+	fs.Warnf("careful")
+	assert.Empty(t, errs.String())
+
+	// A file error only continues if `OnFileError` says so, as it does
+	// if the `FlagSet` continues on failure, unless it is changed
+	path := filepath.Join(u.TempDir(), "lines")
+	assert.NoError(t, os.WriteFile(path, []byte("a\nb\n"), 0o644))
+	r := &testReporter{}
+	codes = []int{}
+	fs = NewFlagSet(WithReporter(r), WithContinueOnFail(),
+		WithExitFunc(func(code int) { codes = append(codes, code) }))
+	fs.Var(&lines, 'f', "file", "read lines from a file", ReadFile())
+	fs.Parse([]string{"-f", path + ".missing"})
+	assert.Equal(t, []ErrorCategory{FileError}, r.categories)
+	assert.Empty(t, codes)
+	fs.OnFileError.ClrContinueBit()
+	fs.Parse([]string{"-f", path + ".missing"})
+	assert.Equal(t, []ErrorCategory{FileError, FileError}, r.categories)
+	assert.Equal(t, []int{DefaultFileErrExitCode}, codes)
+	fs.Parse([]string{"-f", path})
+	assert.Equal(t, []string{"a", "b"}, lines)
+	fs.DumpUsage()
+	assert.Contains(t, r.messages[len(r.messages)-1], "usage: ")
+
+	// Panicking and silence apply to file errors too
+	fs = NewFlagSet(WithPanicOnFail(), WithSilentFail(),
+		WithExitFunc(func(code int) { codes = append(codes, code) }))
+	fs.Var(new([]string), 'f', "file", "read lines from a file", ReadFile())
+	errs.Reset()
+	fs.Output = errs
+	assert.Panics(t, func() { fs.Parse([]string{"-f", path + ".missing"}) })
+	assert.Empty(t, errs.String())
+	assert.Equal(t, []int{DefaultFileErrExitCode}, codes)
+}
+
+func TestReportSetFailures(u *testing.T) {
+	t := assert.TestingT(u)
+	PosixOperandStop = true
+	r := &testReporter{}
+	fs := NewFlagSet(WithReporter(r), WithContinueOnFail())
+	var n int
+	var s string
+	fs.Var(&n, 'n', "number", "a number")
+	fs.Var(&s, 's', "string", "a string", WithCallback(func(f *Flag, arg string, pos int) error {
+		return errors.New("refused")
+	}))
+
+	// A failure reported by the flag itself isn't reported again by
+	// the parser, nor does it stop a later failure being reported
+	assert.Error(t, fs.Lookup('n').Set("x", 0))
+	assert.Len(t, r.messages, 1)
+	fs.Parse([]string{"-s", "y"})
+	assert.Len(t, r.messages, 2)
+	assert.Contains(t, r.messages[1], "refused")
+	fs.Parse([]string{"-n", "z"})
+	assert.Len(t, r.messages, 3)
+
+	// A bad line in a file is a usage error, not a file error
+	path := filepath.Join(u.TempDir(), "numbers")
+	assert.NoError(t, os.WriteFile(path, []byte("1\nx\n"), 0o644))
+	fs.Var(new([]int), 'f', "file", "read numbers from a file", ReadFile())
+	fs.Parse([]string{"-f", path})
+	assert.Len(t, r.messages, 4)
+	assert.Contains(t, r.messages[3], "line 2")
+	assert.Equal(t, UsageError, r.categories[3])
+}
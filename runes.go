@@ -16,13 +16,12 @@ const (
 	ErrRuneIdPartsBad      = -20
 )
 
+// Returns the first rune and the rest of the string. An invalid UTF-8
+// byte is returned as U+FFFD and skipped.
 func FirstRune(s string) (rune, string) {
 	if len(s) == 0 {
 		return ErrRuneEmptyStr, ""
 	}
-	for _, char := range s {
-		return char, s[utf8.RuneLen(char):]
-	}
-	// This is impossible
-	panic("unreachable code")
+	char, size := utf8.DecodeRuneInString(s)
+	return char, s[size:]
 }
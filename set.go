@@ -0,0 +1,221 @@
+package fflag
+
+import (
+	"fmt"
+	"log"
+	"math/bits"
+	"reflect"
+	"strings"
+
+	"github.com/EmmetCaulfield/fflag/pkg/types"
+)
+
+// The keywords standing for every member and for no member of a set
+// (see `AsSet()`).
+const (
+	SetAll  string = "all"
+	SetNone string = "none"
+)
+
+// Option `AsSet()` makes a flag's value a set drawn from the given
+// members, as in `--debug=parse,io`. Each item of the optarg,
+// separated by the list separator, is a member, `all`, or `none`. An
+// optarg beginning with a plain item replaces the set, whereas items
+// prefixed with `+` or `-` add members to or remove them from the set
+// as it stands, e.g. `--debug=+net,-io` or `--debug=all,-io`. The flag
+// is implicitly repeatable, each appearance editing the result of the
+// last.
+//
+// The value must be a `[]string`, which holds the members of the set
+// in the order given here, a `map[string]bool`, which maps each member
+// of the set to `true`, or an integer, in which member `i` is bit `i`
+// (i.e. `1 << i`) of a bitmask. Anything else the value holds is
+// dropped when the set is stored.
+//
+// The option must come before any default, which, like an optarg, is
+// a string such as `parse,io`.
+func AsSet(members ...string) FlagOption {
+	return func(f *Flag) error {
+		if f.IsHyphenNum() || f.IsCounter() || f.HasNArgs() || f.HasCallback() {
+			log.Panicf("'%s' cannot be a set", f)
+		}
+		if len(members) == 0 {
+			log.Panicf("no members given for set '%s'", f)
+		}
+		switch f.Value.(type) {
+		case *[]string, *map[string]bool:
+		default:
+			if !types.IsPointer(f.Value) || !f.IsScalar() || !(types.IsInt(f.Value) || types.IsUint(f.Value)) {
+				log.Panicf("value of set '%s' must be a []string, map[string]bool, or integer, not %T", f, f.Value)
+			}
+			if n := types.BitSize(f.Value); n < len(members) {
+				log.Panicf("%d-bit integer cannot hold %d members for '%s'", n, len(members), f)
+			}
+		}
+		if f.Default != nil {
+			log.Panicf("AsSet() must come before any default for '%s'", f)
+		}
+		seen := map[string]struct{}{}
+		for _, m := range members {
+			if m == "" || m == SetAll || m == SetNone || strings.ContainsAny(m[:1], "+-") || strings.Contains(m, f.ListSeparator) {
+				log.Panicf("invalid member '%s' for set '%s'", m, f)
+			}
+			if _, ok := seen[m]; ok {
+				log.Panicf("duplicate member '%s' for set '%s'", m, f)
+			}
+			seen[m] = struct{}{}
+		}
+		f.Members = members
+		f.Type.SetRepeatsBit()
+		return nil
+	}
+}
+
+// Function `HasMembers()` reports whether a flag is a set (see
+// `AsSet()`).
+func (f *Flag) HasMembers() bool {
+	return len(f.Members) > 0
+}
+
+// Function `memberBits()` returns the current value of a set as a
+// bitmask of its members.
+func (f *Flag) memberBits() uint64 {
+	var mask uint64
+	switch v := f.Value.(type) {
+	case *[]string:
+		for _, item := range *v {
+			if i := f.memberIndex(item); i >= 0 {
+				mask |= 1 << i
+			}
+		}
+	case *map[string]bool:
+		for item, ok := range *v {
+			if i := f.memberIndex(item); ok && i >= 0 {
+				mask |= 1 << i
+			}
+		}
+	default:
+		rv := reflect.ValueOf(f.Value).Elem()
+		if rv.CanInt() {
+			mask = uint64(rv.Int())
+		} else {
+			mask = rv.Uint()
+		}
+		mask &= f.allBits()
+	}
+	return mask
+}
+
+// Function `storeBits()` sets the value of a set from a bitmask of
+// its members.
+func (f *Flag) storeBits(mask uint64) {
+	switch v := f.Value.(type) {
+	case *[]string:
+		*v = (*v)[:0]
+		for i, m := range f.Members {
+			if mask&(1<<i) != 0 {
+				*v = append(*v, m)
+			}
+		}
+	case *map[string]bool:
+		if *v == nil {
+			*v = map[string]bool{}
+		}
+		for item := range *v {
+			delete(*v, item)
+		}
+		for i, m := range f.Members {
+			if mask&(1<<i) != 0 {
+				(*v)[m] = true
+			}
+		}
+	default:
+		rv := reflect.ValueOf(f.Value).Elem()
+		if rv.CanInt() {
+			rv.SetInt(int64(mask))
+		} else {
+			rv.SetUint(mask)
+		}
+	}
+}
+
+func (f *Flag) allBits() uint64 {
+	return ^uint64(0) >> (64 - len(f.Members))
+}
+
+func (f *Flag) memberIndex(item string) int {
+	for i, m := range f.Members {
+		if m == item {
+			return i
+		}
+	}
+	return -1
+}
+
+// Function `editBits()` applies the items of an optarg to the bitmask
+// of a set, returning the new bitmask.
+func (f *Flag) editBits(mask uint64, str string) (uint64, error) {
+	for n, item := range strings.Split(str, f.ListSeparator) {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			if n == 0 {
+				mask = 0
+			}
+			continue
+		}
+		sign := item[0]
+		if sign == '+' || sign == '-' {
+			item = item[1:]
+		} else if n == 0 {
+			mask = 0
+		}
+		var edit uint64
+		switch item {
+		case SetAll:
+			edit = f.allBits()
+		case SetNone:
+			edit, sign = f.allBits(), '-'
+		default:
+			i := f.memberIndex(item)
+			if i < 0 {
+				return mask, fmt.Errorf("'%s' is not one of %s", item, strings.Join(f.Members, ", "))
+			}
+			edit = 1 << i
+		}
+		if sign == '-' {
+			mask &^= edit
+		} else {
+			mask |= edit
+		}
+	}
+	return mask, nil
+}
+
+// Function `testOrSetMembers()` edits the value of a set with the
+// items of an optarg if `doSet` is `true`, otherwise it just checks
+// the items.
+func (f *Flag) testOrSetMembers(str string, doSet bool) error {
+	mask, err := f.editBits(f.memberBits(), str)
+	if err != nil {
+		err = &ValueError{Flag: f, Value: str, Err: err}
+		return f.failf(doSet, err, "%v", err)
+	}
+	if doSet {
+		f.storeBits(mask)
+	}
+	return nil
+}
+
+// Function `memberString()` renders the value of a set as its members
+// separated by the list separator, which can be given back to the flag
+// as an optarg.
+func (f *Flag) memberString() string {
+	mask := f.memberBits()
+	items := make([]string, 0, bits.OnesCount64(mask))
+	for i, m := range f.Members {
+		if mask&(1<<i) != 0 {
+			items = append(items, m)
+		}
+	}
+	return strings.Join(items, f.ListSeparator)
+}
@@ -0,0 +1,81 @@
+package fflag
+
+import (
+	"fmt"
+)
+
+// A `SingleDash` says how an argument beginning with a single hyphen
+// and having more than one rune after it (e.g. `-name`) is
+// interpreted (see `WithSingleDashLongs()`).
+type SingleDash uint8
+
+const (
+	// Always a cluster of short flags, as under POSIX (the default)
+	ClusterOnly SingleDash = iota
+	// A long flag if there is one, otherwise a cluster
+	LongFirst
+	// A cluster if it begins with a short flag, otherwise a long flag
+	ClusterFirst
+)
+
+// Option `WithSingleDashLongs()` lets long flags be given with a single
+// hyphen, as in `find -name` or `xterm -geometry`, the convention of
+// X11 and Go's `flag` package. The precedence says whether an argument
+// like `-name` is tried as a long flag before or after it is tried as
+// a cluster of short flags, and `abbrev` says whether the long flag
+// can be abbreviated, as it can with two hyphens. A long flag that can
+// also be read as a cluster of short flags (e.g. `-all` when `-a` and
+// `-l` are defined) is an error when the flag is added.
+func WithSingleDashLongs(precedence SingleDash, abbrev bool) FlagSetOption {
+	return func(fs *FlagSet) {
+		fs.SingleDash = precedence
+		fs.SingleDashAbbrev = abbrev
+	}
+}
+
+// Function `singleDashLong()` reports whether an apparent cluster of
+// short flags is to be treated as a long flag.
+func (fs *FlagSet) singleDashLong(flags string) bool {
+	if fs.SingleDash == ClusterOnly {
+		return false
+	}
+	f, _ := fs.LongTrie.Get(flags)
+	if f == nil || (!fs.SingleDashAbbrev && f.Long != flags) {
+		return false
+	}
+	if fs.SingleDash == ClusterFirst {
+		r, _ := FirstRune(flags)
+		return fs.LookupShort(r) == nil
+	}
+	return true
+}
+
+// Function `singleDashConflict()` checks that a flag about to be added
+// doesn't make any single-hyphen long flag readable as a cluster of
+// short flags.
+func (fs *FlagSet) singleDashConflict(f *Flag) error {
+	if fs.SingleDash == ClusterOnly {
+		return nil
+	}
+	isCluster := func(long string) bool {
+		for _, r := range long {
+			if _, ok := fs.ShortDict[r]; !ok && r != f.Short {
+				return false
+			}
+		}
+		return true
+	}
+	longs := []string{}
+	if f.Short != NoShort {
+		longs = fs.LongTrie.Keys("")
+	}
+	if f.Long != NoLong {
+		longs = append(longs, f.Long)
+	}
+	for _, long := range longs {
+		if _, tail := FirstRune(long); tail != "" && isCluster(long) {
+			return fmt.Errorf("'-%s' could be a long flag or a cluster of short flags", long)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package fflag
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/EmmetCaulfield/fflag/pkg/types"
+)
+
+// A `Validator` checks a value given to a flag after it has been
+// converted to the flag's type (or, for slices, arrays, and maps, the
+// type of their elements) and returns an error if it is unacceptable
+// (see `WithValidator()`).
+type Validator func(v interface{}) error
+
+// A `Range` is an inclusive range of values given with `WithRange()`,
+// or with `WithRangeStep()`, which also gives the step between them.
+type Range struct {
+	Min, Max, Step interface{}
+}
+
+// Option `WithValidator()` adds a check on the values given to a flag,
+// which is called with each value, converted to the type of the flag
+// or its elements, before it is set. A value that fails any check
+// isn't set and results in a `*ValueError`. Validators compose, so
+// this can be given more than once, along with `WithRange()`,
+// `WithRangeStep()`, and `WithPattern()`.
+func WithValidator(v Validator) FlagOption {
+	return func(f *Flag) error {
+		if v == nil {
+			log.Panicf("nil validator for '%s'", f)
+		}
+		if f.HasCallback() || types.IsSetter(f.Value) {
+			log.Panicf("cannot validate values of '%s', which are not converted", f)
+		}
+		f.Validators = append(f.Validators, v)
+		return nil
+	}
+}
+
+// Option `WithRange()` requires the values given to a numeric flag
+// (including durations and sizes) to be between `min` and `max`
+// inclusive, e.g. `WithRange(1, 65535)` for a port number. The range
+// is shown in help/usage text.
+func WithRange(min, max interface{}) FlagOption {
+	return func(f *Flag) error {
+		if !f.IsNumber() && !types.IsDuration(f.Value) && !types.IsSize(f.Value) {
+			log.Panicf("cannot give a range for non-numeric value of '%s'", f)
+		}
+		c, err := types.Compare(min, max)
+		if err != nil || c > 0 {
+			log.Panicf("invalid range %v..%v for '%s'", min, max, f)
+		}
+		f.Ranges = append(f.Ranges, Range{Min: min, Max: max})
+		return WithValidator(func(v interface{}) error {
+			lo, _ := types.Compare(v, min)
+			hi, _ := types.Compare(v, max)
+			if lo < 0 || hi > 0 {
+				return fmt.Errorf("out of range %s", rangeString(min, max))
+			}
+			return nil
+		})(f)
+	}
+}
+
+// Option `WithRangeStep()` is like `WithRange()`, but also requires
+// the values to be `min` plus a whole number of steps of size `step`,
+// e.g. `WithRangeStep(0, 100, 5)` for a percentage in fives. The step
+// is shown in help/usage text along with the range.
+func WithRangeStep(min, max, step interface{}) FlagOption {
+	return func(f *Flag) error {
+		if err := WithRange(min, max)(f); err != nil {
+			return err
+		}
+		if c, err := types.Compare(step, 0); err != nil || c <= 0 {
+			log.Panicf("invalid step %v for '%s'", step, f)
+		}
+		f.Ranges[len(f.Ranges)-1].Step = step
+		return WithValidator(func(v interface{}) error {
+			if ok, _ := types.OnStep(v, min, step); !ok {
+				return fmt.Errorf("not in steps of %s from %s", types.StrConv(step), types.StrConv(min))
+			}
+			return nil
+		})(f)
+	}
+}
+
+// Option `WithPattern()` requires the values given to a flag, as
+// strings, to match the regular expression, which should usually be
+// anchored (e.g. `^[a-z]+$`).
+func WithPattern(re *regexp.Regexp) FlagOption {
+	return WithValidator(func(v interface{}) error {
+		if s := types.StrConv(v); !re.MatchString(s) {
+			return fmt.Errorf("'%s' does not match /%s/", s, re)
+		}
+		return nil
+	})
+}
+
+func rangeString(min, max interface{}) string {
+	return types.StrConv(min) + ".." + types.StrConv(max)
+}
+
+// Function `rangesString()` describes the ranges given with
+// `WithRange()` and `WithRangeStep()` for help/usage text.
+func (f *Flag) rangesString() string {
+	strs := make([]string, len(f.Ranges))
+	for i, r := range f.Ranges {
+		strs[i] = rangeString(r.Min, r.Max)
+		if r.Step != nil {
+			strs[i] += " by " + types.StrConv(r.Step)
+		}
+	}
+	return strings.Join(strs, ", ")
+}
+
+// Function `validate()` converts an optarg into a scratch value of the
+// flag's type, so that nothing (e.g. the keys seen by a map-valued
+// flag) is changed, and runs the validators on it or on each of its
+// elements.
+func (f *Flag) validate(str string) error {
+	if len(f.Validators) == 0 {
+		return nil
+	}
+	scratch := reflect.New(reflect.TypeOf(f.Value).Elem())
+	opts := append(f.convOptions(), types.WithSeenKeys(nil))
+	if err := types.FromStr(scratch.Interface(), str, true, opts...); err != nil {
+		return err
+	}
+	// Not `rv.Kind()`, since a scalar such as a `net.IP` may be a slice
+	rv := scratch.Elem()
+	if types.IsMap(f.Value) {
+		iter := rv.MapRange()
+		for iter.Next() {
+			if err := f.runValidators(iter.Value().Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if !f.IsScalar() {
+		for i := 0; i < rv.Len(); i++ {
+			if err := f.runValidators(rv.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return f.runValidators(rv.Interface())
+}
+
+// Function `validateItem()` converts one option-argument of a flag
+// taking several (see `WithNArgs()`) into a scratch element and runs
+// the validators on it.
+func (f *Flag) validateItem(arg string) error {
+	if len(f.Validators) == 0 {
+		return nil
+	}
+	scratch := reflect.New(reflect.TypeOf(f.Value).Elem().Elem())
+	if err := types.FromStr(scratch.Interface(), arg, true, f.convOptions()...); err != nil {
+		return err
+	}
+	return f.runValidators(scratch.Elem().Interface())
+}
+
+func (f *Flag) runValidators(v interface{}) error {
+	for _, validator := range f.Validators {
+		if err := validator(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}